@@ -1,21 +1,44 @@
 package api
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/cors"
 	"standmaster/api/controller"
+	"standmaster/internal/allowance"
+	"standmaster/internal/cash"
+	"standmaster/internal/childrule"
 	"standmaster/internal/interaction"
+	"standmaster/internal/inventory"
 	"standmaster/internal/kermesse"
+	"standmaster/internal/ledger"
+	"standmaster/internal/media"
+	"standmaster/internal/notification"
+	"standmaster/internal/offlinesale"
+	"standmaster/internal/paytoken"
+	"standmaster/internal/product"
+	"standmaster/internal/promotion"
+	"standmaster/internal/queue"
+	"standmaster/internal/scoring"
 	"standmaster/internal/stand"
+	"standmaster/internal/stock"
+	"standmaster/internal/tag"
 	"standmaster/internal/ticket"
 	"standmaster/internal/tombola"
+	"standmaster/internal/topup"
 	"standmaster/internal/user"
+	"standmaster/internal/webhook"
+	"standmaster/pkg/scheduler"
+	"standmaster/third_party/database"
+	"standmaster/third_party/payment"
 	"standmaster/third_party/resend"
+	"standmaster/third_party/storage"
 )
 
 type APIServer struct {
@@ -35,42 +58,142 @@ func (s *APIServer) Start() error {
 
 	resendService := resend.NewResendService(os.Getenv("RESEND_API_KEY"), os.Getenv("RESEND_FROM_EMAIL"))
 
+	var paymentProvider payment.Provider
+	var fakePaymentProvider *payment.Fake
+	if os.Getenv("PAYMENT_PROVIDER") == "fake" {
+		fakePaymentProvider = payment.NewFakeProvider()
+		paymentProvider = fakePaymentProvider
+	} else {
+		paymentProvider = payment.NewStripeProvider(os.Getenv("STRIPE_SECRET_KEY"), os.Getenv("STRIPE_WEBHOOK_SECRET"), os.Getenv("STRIPE_SUCCESS_URL"), os.Getenv("STRIPE_CANCEL_URL"))
+	}
+
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	}).Methods(http.MethodGet)
 
+	fileStorage := storage.NewLocalStorage(os.Getenv("STORAGE_LOCAL_DIR"), os.Getenv("STORAGE_PUBLIC_URL"))
+	if handler := fileStorage.Handler(); handler != nil {
+		router.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", handler)).Methods(http.MethodGet)
+	}
+	mediaService := media.NewService(fileStorage)
+
+	transactor := database.NewTransactor(s.db)
+	ledgerRepository := ledger.NewRepository(s.db)
+	stockRepository := stock.NewRepository(s.db)
+
 	userRepository := user.NewRepository(s.db)
-	userService := user.NewService(userRepository, resendService)
+	userService := user.NewService(userRepository, ledgerRepository, resendService, transactor)
 	userController := controller.NewUserController(userService, userRepository)
 	userController.RegisterRoutes(router)
 
+	notificationRepository := notification.NewRepository(s.db)
+	notifier := notification.Notifiers{
+		notification.NewInAppNotifier(notificationRepository),
+		notification.NewEmailNotifier(resendService, userRepository),
+	}
+	notificationService := notification.NewService(notificationRepository)
+	notificationController := controller.NewNotificationController(notificationService, userRepository)
+	notificationController.RegisterRoutes(router)
+
+	tagRepository := tag.NewRepository(s.db)
+	tagService := tag.NewService(tagRepository, userRepository, transactor)
+	tagController := controller.NewTagController(tagService, userRepository)
+	tagController.RegisterRoutes(router)
+
+	kermesseRepository := kermesse.NewRepository(s.db)
+
 	standRepository := stand.NewRepository(s.db)
-	standService := stand.NewService(standRepository)
+	standService := stand.NewService(standRepository, userRepository, tagRepository, stockRepository, kermesseRepository, mediaService, transactor)
 	standController := controller.NewStandController(standService, userRepository)
 	standController.RegisterRoutes(router)
 
-	kermesseRepository := kermesse.NewRepository(s.db)
-	kermesseService := kermesse.NewService(kermesseRepository, userRepository)
+	kermesseService := kermesse.NewService(kermesseRepository, userRepository, mediaService, notifier, transactor)
 	kermesseController := controller.NewKermesseController(kermesseService, userRepository)
 	kermesseController.RegisterRoutes(router)
 
+	productRepository := product.NewRepository(s.db)
+	productService := product.NewService(productRepository, standRepository, stockRepository, transactor)
+	productController := controller.NewProductController(productService, userRepository)
+	productController.RegisterRoutes(router)
+
+	inventoryService := inventory.NewService(stockRepository, standRepository, productRepository, kermesseRepository, notifier, transactor)
+	inventoryController := controller.NewInventoryController(inventoryService, userRepository)
+	inventoryController.RegisterRoutes(router)
+
+	promotionRepository := promotion.NewRepository(s.db)
+	promotionService := promotion.NewService(promotionRepository, standRepository, productRepository)
+	promotionController := controller.NewPromotionController(promotionService, userRepository)
+	promotionController.RegisterRoutes(router)
+
+	childRuleRepository := childrule.NewRepository(s.db)
+	childRuleService := childrule.NewService(childRuleRepository, userRepository, standRepository)
+	childRuleController := controller.NewChildRuleController(childRuleService, userRepository)
+	childRuleController.RegisterRoutes(router)
+
+	scoringRepository := scoring.NewRepository(s.db)
+	scoringService := scoring.NewService(scoringRepository, kermesseRepository, standRepository, transactor)
+	scoringController := controller.NewScoringController(scoringService, userRepository)
+	scoringController.RegisterRoutes(router)
+
 	interactionRepository := interaction.NewRepository(s.db)
-	interactionService := interaction.NewService(interactionRepository, standRepository, userRepository, kermesseRepository)
+	interactionService := interaction.NewService(interactionRepository, standRepository, productRepository, userRepository, kermesseRepository, ledgerRepository, childRuleRepository, scoringRepository, tagRepository, promotionRepository, stockRepository, inventoryService, transactor)
 	interactionController := controller.NewInteractionController(interactionService, userRepository)
 	interactionController.RegisterRoutes(router)
 
+	queueRepository := queue.NewRepository(s.db)
+	queueService := queue.NewService(queueRepository, standRepository, userRepository, interactionRepository, interactionService, transactor)
+	queueController := controller.NewQueueController(queueService, userRepository)
+	queueController.RegisterRoutes(router)
+
+	payTokenRepository := paytoken.NewRepository(s.db)
+	payTokenService := paytoken.NewService(payTokenRepository, standRepository, interactionService, transactor)
+	payTokenController := controller.NewPayTokenController(payTokenService, userRepository)
+	payTokenController.RegisterRoutes(router)
+
+	offlineSaleRepository := offlinesale.NewRepository(s.db)
+	offlineSaleService := offlinesale.NewService(offlineSaleRepository, standRepository, kermesseRepository, userRepository, interactionService)
+	offlineSaleController := controller.NewOfflineSaleController(offlineSaleService, userRepository)
+	offlineSaleController.RegisterRoutes(router)
+
 	tombolaRepository := tombola.NewRepository(s.db)
 	tombolaService := tombola.NewService(tombolaRepository, kermesseRepository)
 	tombolaController := controller.NewTombolaController(tombolaService, userRepository)
 	tombolaController.RegisterRoutes(router)
 
 	ticketRepository := ticket.NewRepository(s.db)
-	ticketService := ticket.NewService(ticketRepository, tombolaRepository, userRepository)
+	ticketService := ticket.NewService(ticketRepository, tombolaRepository, kermesseRepository, userRepository, ledgerRepository, childRuleRepository, transactor)
 	ticketController := controller.NewTicketController(ticketService, userRepository)
 	ticketController.RegisterRoutes(router)
 
-	router.HandleFunc("/webhook", controller.HandleWebhook(userService)).Methods(http.MethodPost)
+	topUpRepository := topup.NewRepository(s.db)
+	topUpService := topup.NewService(topUpRepository, ledgerRepository, paymentProvider, transactor)
+	topUpController := controller.NewTopUpController(topUpService, userRepository)
+	topUpController.RegisterRoutes(router)
+
+	cashRepository := cash.NewRepository(s.db)
+	cashService := cash.NewService(cashRepository, kermesseRepository, userRepository, ledgerRepository, transactor)
+	cashController := controller.NewCashController(cashService, userRepository)
+	cashController.RegisterRoutes(router)
+
+	allowanceRepository := allowance.NewRepository(s.db)
+	allowanceService := allowance.NewService(allowanceRepository, userRepository, kermesseRepository, ledgerRepository, transactor)
+	allowanceController := controller.NewAllowanceController(allowanceService, userRepository)
+	allowanceController.RegisterRoutes(router)
+
+	webhookRepository := webhook.NewRepository(s.db)
+	webhookService := webhook.NewService(webhookRepository, topUpService)
+	webhookHandler := controller.HandleWebhook(webhookService, paymentProvider)
+	router.HandleFunc("/webhook", webhookHandler).Methods(http.MethodPost)
+	if fakePaymentProvider != nil {
+		router.HandleFunc("/payment/fake/{session_id}/complete", fakePaymentProvider.CompletePayment(webhookHandler)).Methods(http.MethodPost)
+	}
+
+	// background jobs
+	go scheduler.Every(context.Background(), "interactions approval expiry", time.Minute, interactionService.ExpirePending)
+	go scheduler.Every(context.Background(), "allowances", time.Minute, allowanceService.RunDue)
+	go scheduler.Every(context.Background(), "kermesses run", time.Minute, kermesseService.RunDue)
+	go scheduler.Every(context.Background(), "kermesses close", time.Minute, kermesseService.CloseDue)
 
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"standmaster/api/middleware"
+	"standmaster/internal/allowance"
+	"standmaster/internal/models"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/json"
+)
+
+type AllowanceController struct {
+	service        allowance.AllowanceService
+	userRepository user.UserRepository
+}
+
+func NewAllowanceController(service allowance.AllowanceService, userRepository user.UserRepository) *AllowanceController {
+	return &AllowanceController{
+		service:        service,
+		userRepository: userRepository,
+	}
+}
+
+func (h *AllowanceController) RegisterRoutes(mux *mux.Router) {
+	mux.Handle("/user/children/{child_id}/allowances", errors.ErrorHandler(middleware.IsAuth(h.GetAll, h.userRepository, models.UserRoleParent))).Methods(http.MethodGet)
+	mux.Handle("/user/children/{child_id}/allowances", errors.ErrorHandler(middleware.IsAuth(h.Create, h.userRepository, models.UserRoleParent))).Methods(http.MethodPost)
+	mux.Handle("/user/children/{child_id}/allowances/{id}", errors.ErrorHandler(middleware.IsAuth(h.Update, h.userRepository, models.UserRoleParent))).Methods(http.MethodPatch)
+	mux.Handle("/user/children/{child_id}/allowances/{id}", errors.ErrorHandler(middleware.IsAuth(h.Delete, h.userRepository, models.UserRoleParent))).Methods(http.MethodDelete)
+	mux.Handle("/user/children/{child_id}/allowances/{id}/runs", errors.ErrorHandler(middleware.IsAuth(h.GetRuns, h.userRepository, models.UserRoleParent))).Methods(http.MethodGet)
+}
+
+func (h *AllowanceController) GetAll(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	childId, err := strconv.Atoi(queryParams["child_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	allowances, err := h.service.GetAll(r.Context(), childId)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, allowances); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *AllowanceController) GetRuns(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	childId, err := strconv.Atoi(queryParams["child_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	runs, err := h.service.GetRuns(r.Context(), childId, id)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, runs); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *AllowanceController) Create(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	childId, err := strconv.Atoi(queryParams["child_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Create(r.Context(), childId, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusCreated, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *AllowanceController) Update(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	childId, err := strconv.Atoi(queryParams["child_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Update(r.Context(), childId, id, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *AllowanceController) Delete(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	childId, err := strconv.Atoi(queryParams["child_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Delete(r.Context(), childId, id); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
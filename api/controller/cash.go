@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"standmaster/api/middleware"
+	"standmaster/internal/cash"
+	"standmaster/internal/models"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/json"
+)
+
+type CashController struct {
+	service        cash.CashService
+	userRepository user.UserRepository
+}
+
+func NewCashController(service cash.CashService, userRepository user.UserRepository) *CashController {
+	return &CashController{
+		service:        service,
+		userRepository: userRepository,
+	}
+}
+
+func (h *CashController) RegisterRoutes(mux *mux.Router) {
+	mux.Handle("/kermesse/{id}/cash", errors.ErrorHandler(middleware.IsAuth(h.Create, h.userRepository, models.UserRoleOrganizer))).Methods(http.MethodPost)
+	mux.Handle("/kermesse/{id}/cash/report", errors.ErrorHandler(middleware.IsAuth(h.Report, h.userRepository, models.UserRoleOrganizer))).Methods(http.MethodGet)
+}
+
+func (h *CashController) Create(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	topUp, err := h.service.Create(r.Context(), id, input)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusCreated, topUp); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *CashController) Report(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	reports, err := h.service.Report(r.Context(), id, r.URL.Query().Get("date"))
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, reports); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
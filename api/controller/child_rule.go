@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"standmaster/api/middleware"
+	"standmaster/internal/childrule"
+	"standmaster/internal/models"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/json"
+)
+
+type ChildRuleController struct {
+	service        childrule.ChildRuleService
+	userRepository user.UserRepository
+}
+
+func NewChildRuleController(service childrule.ChildRuleService, userRepository user.UserRepository) *ChildRuleController {
+	return &ChildRuleController{
+		service:        service,
+		userRepository: userRepository,
+	}
+}
+
+func (h *ChildRuleController) RegisterRoutes(mux *mux.Router) {
+	mux.Handle("/user/children/{child_id}/rules", errors.ErrorHandler(middleware.IsAuth(h.GetAll, h.userRepository, models.UserRoleParent))).Methods(http.MethodGet)
+	mux.Handle("/user/children/{child_id}/rules", errors.ErrorHandler(middleware.IsAuth(h.Create, h.userRepository, models.UserRoleParent))).Methods(http.MethodPost)
+	mux.Handle("/user/children/{child_id}/rules/{id}", errors.ErrorHandler(middleware.IsAuth(h.Update, h.userRepository, models.UserRoleParent))).Methods(http.MethodPatch)
+	mux.Handle("/user/children/{child_id}/rules/{id}", errors.ErrorHandler(middleware.IsAuth(h.Delete, h.userRepository, models.UserRoleParent))).Methods(http.MethodDelete)
+}
+
+func (h *ChildRuleController) GetAll(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	childId, err := strconv.Atoi(queryParams["child_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	rules, err := h.service.GetAll(r.Context(), childId)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, rules); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *ChildRuleController) Create(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	childId, err := strconv.Atoi(queryParams["child_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Create(r.Context(), childId, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusCreated, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *ChildRuleController) Update(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	childId, err := strconv.Atoi(queryParams["child_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Update(r.Context(), childId, id, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *ChildRuleController) Delete(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	childId, err := strconv.Atoi(queryParams["child_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Delete(r.Context(), childId, id); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
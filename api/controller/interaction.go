@@ -30,7 +30,12 @@ func (h *InteractionController) RegisterRoutes(mux *mux.Router) {
 	mux.Handle("/interaction", errors.ErrorHandler(middleware.IsAuth(h.Create, h.userRepository, models.UserRoleParent, models.UserRoleChild))).Methods(http.MethodPost)
 	mux.Handle("/interaction/{id}", errors.ErrorHandler(middleware.IsAuth(h.Get, h.userRepository))).Methods(http.MethodGet)
 	mux.Handle("/interaction/{id}", errors.ErrorHandler(middleware.IsAuth(h.Update, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPatch)
+	mux.Handle("/interaction/{id}/refund", errors.ErrorHandler(middleware.IsAuth(h.Refund, h.userRepository, models.UserRoleStandHolder, models.UserRoleOrganizer))).Methods(http.MethodPatch)
 	mux.Handle("/interactions", errors.ErrorHandler(middleware.IsAuth(h.GetAll, h.userRepository))).Methods(http.MethodGet)
+
+	mux.Handle("/user/children/interactions/pending", errors.ErrorHandler(middleware.IsAuth(h.GetAllPending, h.userRepository, models.UserRoleParent))).Methods(http.MethodGet)
+	mux.Handle("/user/children/interactions/{id}/approve", errors.ErrorHandler(middleware.IsAuth(h.Approve, h.userRepository, models.UserRoleParent))).Methods(http.MethodPatch)
+	mux.Handle("/user/children/interactions/{id}/reject", errors.ErrorHandler(middleware.IsAuth(h.Reject, h.userRepository, models.UserRoleParent))).Methods(http.MethodPatch)
 }
 
 func (h *InteractionController) Create(w http.ResponseWriter, r *http.Request) error {
@@ -42,11 +47,12 @@ func (h *InteractionController) Create(w http.ResponseWriter, r *http.Request) e
 		}
 	}
 
-	if err := h.service.Create(r.Context(), input); err != nil {
+	interaction, err := h.service.Create(r.Context(), input)
+	if err != nil {
 		return err
 	}
 
-	if err := json.Write(w, http.StatusCreated, nil); err != nil {
+	if err := json.Write(w, http.StatusCreated, interaction); err != nil {
 		return errors.CustomError{
 			Key: errors.InternalServerError,
 			Err: err,
@@ -88,6 +94,38 @@ func (h *InteractionController) Update(w http.ResponseWriter, r *http.Request) e
 	return nil
 }
 
+func (h *InteractionController) Refund(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Refund(r.Context(), id, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
 func (h *InteractionController) GetAll(w http.ResponseWriter, r *http.Request) error {
 	interactions, err := h.service.GetAll(r.Context(), utils.GetQueryParams(r))
 	if err != nil {
@@ -128,3 +166,67 @@ func (h *InteractionController) Get(w http.ResponseWriter, r *http.Request) erro
 
 	return nil
 }
+
+func (h *InteractionController) GetAllPending(w http.ResponseWriter, r *http.Request) error {
+	interactions, err := h.service.GetAllPending(r.Context())
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, interactions); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *InteractionController) Approve(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Approve(r.Context(), id); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *InteractionController) Reject(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Reject(r.Context(), id); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
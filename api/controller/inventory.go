@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"standmaster/api/middleware"
+	"standmaster/internal/inventory"
+	"standmaster/internal/models"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/json"
+	"standmaster/pkg/utils"
+)
+
+type InventoryController struct {
+	service        inventory.InventoryService
+	userRepository user.UserRepository
+}
+
+func NewInventoryController(service inventory.InventoryService, userRepository user.UserRepository) *InventoryController {
+	return &InventoryController{
+		service:        service,
+		userRepository: userRepository,
+	}
+}
+
+func (h *InventoryController) RegisterRoutes(mux *mux.Router) {
+	mux.Handle("/stand/{id}/stock/movements", errors.ErrorHandler(middleware.IsAuth(h.GetMovements, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodGet)
+	mux.Handle("/stand/{id}/stock/restock", errors.ErrorHandler(middleware.IsAuth(h.Restock, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPost)
+	mux.Handle("/stand/{id}/stock/adjust", errors.ErrorHandler(middleware.IsAuth(h.Adjust, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPost)
+	mux.Handle("/stand/{id}/stock/report", errors.ErrorHandler(middleware.IsAuth(h.GetReport, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodGet)
+	mux.Handle("/stand/{id}/stock/threshold", errors.ErrorHandler(middleware.IsAuth(h.SetThreshold, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPatch)
+}
+
+func (h *InventoryController) GetMovements(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	standId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	movements, err := h.service.GetMovements(r.Context(), standId, utils.GetQueryParams(r))
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, movements); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *InventoryController) Restock(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	standId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Restock(r.Context(), standId, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusCreated, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *InventoryController) Adjust(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	standId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Adjust(r.Context(), standId, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusCreated, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *InventoryController) GetReport(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	standId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	kermesseId, err := strconv.Atoi(r.URL.Query().Get("kermesse_id"))
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+
+	report, err := h.service.GetReport(r.Context(), standId, kermesseId)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, report); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *InventoryController) SetThreshold(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	standId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.SetThreshold(r.Context(), standId, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"standmaster/internal/user"
 	"standmaster/pkg/errors"
 	"standmaster/pkg/json"
+	"standmaster/pkg/utils"
 )
 
 type KermesseController struct {
@@ -31,7 +32,10 @@ func (h *KermesseController) RegisterRoutes(mux *mux.Router) {
 	mux.Handle("/kermesse/{id}", errors.ErrorHandler(middleware.IsAuth(h.Get, h.userRepository))).Methods(http.MethodGet)
 	mux.Handle("/kermesses", errors.ErrorHandler(middleware.IsAuth(h.GetAll, h.userRepository))).Methods(http.MethodGet)
 	mux.Handle("/kermesse/{id}/users", errors.ErrorHandler(middleware.IsAuth(h.GetUsersInvite, h.userRepository))).Methods(http.MethodGet)
+	mux.Handle("/kermesse/{id}/image", errors.ErrorHandler(middleware.IsAuth(h.UploadImage, h.userRepository, models.UserRoleOrganizer))).Methods(http.MethodPost)
 	mux.Handle("/kermesse/{id}/finish", errors.ErrorHandler(middleware.IsAuth(h.End, h.userRepository, models.UserRoleOrganizer))).Methods(http.MethodPatch)
+	mux.Handle("/kermesse/{id}/status", errors.ErrorHandler(middleware.IsAuth(h.SetStatus, h.userRepository, models.UserRoleOrganizer))).Methods(http.MethodPatch)
+	mux.Handle("/kermesse/{id}/history", errors.ErrorHandler(middleware.IsAuth(h.GetHistory, h.userRepository, models.UserRoleOrganizer))).Methods(http.MethodGet)
 	mux.Handle("/kermesse/{id}/adduser", errors.ErrorHandler(middleware.IsAuth(h.AddUser, h.userRepository, models.UserRoleOrganizer))).Methods(http.MethodPatch)
 	mux.Handle("/kermesse/{id}/addstand", errors.ErrorHandler(middleware.IsAuth(h.AddStand, h.userRepository, models.UserRoleOrganizer))).Methods(http.MethodPatch)
 }
@@ -117,7 +121,7 @@ func (h *KermesseController) Get(w http.ResponseWriter, r *http.Request) error {
 }
 
 func (h *KermesseController) GetAll(w http.ResponseWriter, r *http.Request) error {
-	kermesses, err := h.service.GetAll(r.Context())
+	kermesses, err := h.service.GetAll(r.Context(), utils.GetQueryParams(r))
 	if err != nil {
 		return err
 	}
@@ -181,6 +185,63 @@ func (h *KermesseController) End(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+func (h *KermesseController) SetStatus(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.SetStatus(r.Context(), id, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *KermesseController) GetHistory(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	changes, err := h.service.GetHistory(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, changes); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
 func (h *KermesseController) AddUser(w http.ResponseWriter, r *http.Request) error {
 	queryParams := mux.Vars(r)
 	id, err := strconv.Atoi(queryParams["id"])
@@ -246,3 +307,34 @@ func (h *KermesseController) AddStand(w http.ResponseWriter, r *http.Request) er
 
 	return nil
 }
+
+func (h *KermesseController) UploadImage(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	file, err := formFile(w, r, "image")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	kermesse, err := h.service.UploadImage(r.Context(), id, file)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, kermesse); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"standmaster/api/middleware"
+	"standmaster/internal/notification"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/json"
+	"standmaster/pkg/utils"
+)
+
+type NotificationController struct {
+	service        notification.NotificationService
+	userRepository user.UserRepository
+}
+
+func NewNotificationController(service notification.NotificationService, userRepository user.UserRepository) *NotificationController {
+	return &NotificationController{
+		service:        service,
+		userRepository: userRepository,
+	}
+}
+
+func (h *NotificationController) RegisterRoutes(mux *mux.Router) {
+	mux.Handle("/user/me/notifications", errors.ErrorHandler(middleware.IsAuth(h.GetAll, h.userRepository))).Methods(http.MethodGet)
+	mux.Handle("/user/me/notifications/{id}/read", errors.ErrorHandler(middleware.IsAuth(h.Read, h.userRepository))).Methods(http.MethodPatch)
+}
+
+func (h *NotificationController) GetAll(w http.ResponseWriter, r *http.Request) error {
+	notifications, err := h.service.GetAll(r.Context(), utils.GetQueryParams(r))
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, notifications); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *NotificationController) Read(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Read(r.Context(), id); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
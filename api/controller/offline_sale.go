@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"standmaster/api/middleware"
+	"standmaster/internal/models"
+	"standmaster/internal/offlinesale"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/json"
+	"standmaster/pkg/utils"
+)
+
+type OfflineSaleController struct {
+	service        offlinesale.OfflineSaleService
+	userRepository user.UserRepository
+}
+
+func NewOfflineSaleController(service offlinesale.OfflineSaleService, userRepository user.UserRepository) *OfflineSaleController {
+	return &OfflineSaleController{
+		service:        service,
+		userRepository: userRepository,
+	}
+}
+
+func (h *OfflineSaleController) RegisterRoutes(mux *mux.Router) {
+	mux.Handle("/stand/{id}/sync", errors.ErrorHandler(middleware.IsAuth(h.Sync, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPost)
+	mux.Handle("/kermesse/{id}/offline-sales", errors.ErrorHandler(middleware.IsAuth(h.GetAll, h.userRepository, models.UserRoleOrganizer))).Methods(http.MethodGet)
+	mux.Handle("/kermesse/{id}/offline-sales/{sale_id}/resolve", errors.ErrorHandler(middleware.IsAuth(h.Resolve, h.userRepository, models.UserRoleOrganizer))).Methods(http.MethodPatch)
+}
+
+func (h *OfflineSaleController) Sync(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	standId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	results, err := h.service.Sync(r.Context(), standId, input)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, results); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *OfflineSaleController) GetAll(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	kermesseId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	sales, err := h.service.GetAll(r.Context(), kermesseId, utils.GetQueryParams(r))
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, sales); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *OfflineSaleController) Resolve(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	kermesseId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	id, err := strconv.Atoi(queryParams["sale_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Resolve(r.Context(), kermesseId, id); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
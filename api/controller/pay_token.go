@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/skip2/go-qrcode"
+	"standmaster/api/middleware"
+	"standmaster/internal/models"
+	"standmaster/internal/paytoken"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/json"
+)
+
+type PayTokenController struct {
+	service        paytoken.PayTokenService
+	userRepository user.UserRepository
+}
+
+func NewPayTokenController(service paytoken.PayTokenService, userRepository user.UserRepository) *PayTokenController {
+	return &PayTokenController{
+		service:        service,
+		userRepository: userRepository,
+	}
+}
+
+func (h *PayTokenController) RegisterRoutes(mux *mux.Router) {
+	mux.Handle("/user/me/paytoken", errors.ErrorHandler(middleware.IsAuth(h.Create, h.userRepository, models.UserRoleChild))).Methods(http.MethodGet)
+	mux.Handle("/interaction/paytoken", errors.ErrorHandler(middleware.IsAuth(h.Charge, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPost)
+}
+
+// Create renders a new payment token of the child as a QR code.
+func (h *PayTokenController) Create(w http.ResponseWriter, r *http.Request) error {
+	token, err := h.service.Create(r.Context())
+	if err != nil {
+		return err
+	}
+
+	png, err := qrcode.Encode(token, qrcode.Medium, 256)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(png); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *PayTokenController) Charge(w http.ResponseWriter, r *http.Request) error {
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	interaction, err := h.service.Charge(r.Context(), input)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusCreated, interaction); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
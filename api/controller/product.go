@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"standmaster/api/middleware"
+	"standmaster/internal/models"
+	"standmaster/internal/product"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/json"
+)
+
+type ProductController struct {
+	service        product.ProductService
+	userRepository user.UserRepository
+}
+
+func NewProductController(service product.ProductService, userRepository user.UserRepository) *ProductController {
+	return &ProductController{
+		service:        service,
+		userRepository: userRepository,
+	}
+}
+
+func (h *ProductController) RegisterRoutes(mux *mux.Router) {
+	mux.Handle("/stand/{id}/products", errors.ErrorHandler(middleware.IsAuth(h.GetAll, h.userRepository))).Methods(http.MethodGet)
+	mux.Handle("/stand/{id}/products", errors.ErrorHandler(middleware.IsAuth(h.Create, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPost)
+	mux.Handle("/stand/{id}/products/{product_id}", errors.ErrorHandler(middleware.IsAuth(h.Update, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPatch)
+}
+
+func (h *ProductController) GetAll(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	standId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	products, err := h.service.GetAll(r.Context(), standId)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, products); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *ProductController) Create(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	standId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Create(r.Context(), standId, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusCreated, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *ProductController) Update(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	standId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	id, err := strconv.Atoi(queryParams["product_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Update(r.Context(), standId, id, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"standmaster/api/middleware"
+	"standmaster/internal/models"
+	"standmaster/internal/promotion"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/json"
+)
+
+type PromotionController struct {
+	service        promotion.PromotionService
+	userRepository user.UserRepository
+}
+
+func NewPromotionController(service promotion.PromotionService, userRepository user.UserRepository) *PromotionController {
+	return &PromotionController{
+		service:        service,
+		userRepository: userRepository,
+	}
+}
+
+func (h *PromotionController) RegisterRoutes(mux *mux.Router) {
+	mux.Handle("/stand/{id}/promotions", errors.ErrorHandler(middleware.IsAuth(h.GetAll, h.userRepository))).Methods(http.MethodGet)
+	mux.Handle("/stand/{id}/promotions", errors.ErrorHandler(middleware.IsAuth(h.Create, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPost)
+	mux.Handle("/stand/{id}/promotions/{promotion_id}", errors.ErrorHandler(middleware.IsAuth(h.Update, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPatch)
+	mux.Handle("/stand/{id}/promotions/{promotion_id}", errors.ErrorHandler(middleware.IsAuth(h.Delete, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodDelete)
+}
+
+func (h *PromotionController) GetAll(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	standId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	promotions, err := h.service.GetAll(r.Context(), standId)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, promotions); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *PromotionController) Create(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	standId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Create(r.Context(), standId, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusCreated, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *PromotionController) Update(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	standId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	id, err := strconv.Atoi(queryParams["promotion_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Update(r.Context(), standId, id, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *PromotionController) Delete(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	standId, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	id, err := strconv.Atoi(queryParams["promotion_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Delete(r.Context(), standId, id); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"standmaster/api/middleware"
+	"standmaster/internal/models"
+	"standmaster/internal/queue"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/json"
+)
+
+type QueueController struct {
+	service        queue.QueueService
+	userRepository user.UserRepository
+}
+
+func NewQueueController(service queue.QueueService, userRepository user.UserRepository) *QueueController {
+	return &QueueController{
+		service:        service,
+		userRepository: userRepository,
+	}
+}
+
+func (h *QueueController) RegisterRoutes(mux *mux.Router) {
+	mux.Handle("/queues", errors.ErrorHandler(middleware.IsAuth(h.GetMine, h.userRepository, models.UserRoleParent, models.UserRoleChild))).Methods(http.MethodGet)
+	mux.Handle("/queues/{id}", errors.ErrorHandler(middleware.IsAuth(h.Leave, h.userRepository, models.UserRoleParent, models.UserRoleChild))).Methods(http.MethodDelete)
+	mux.Handle("/stand/{id}/queue", errors.ErrorHandler(middleware.IsAuth(h.GetAll, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodGet)
+	mux.Handle("/stand/{id}/queue", errors.ErrorHandler(middleware.IsAuth(h.Join, h.userRepository, models.UserRoleParent, models.UserRoleChild))).Methods(http.MethodPost)
+	mux.Handle("/stand/{id}/queue/next", errors.ErrorHandler(middleware.IsAuth(h.CallNext, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPost)
+	mux.Handle("/stand/{id}/queue/{entry_id}/no-show", errors.ErrorHandler(middleware.IsAuth(h.NoShow, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPatch)
+}
+
+func (h *QueueController) GetMine(w http.ResponseWriter, r *http.Request) error {
+	entries, err := h.service.GetMine(r.Context())
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, entries); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *QueueController) Leave(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Leave(r.Context(), id); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *QueueController) GetAll(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	entries, err := h.service.GetAll(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, entries); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *QueueController) Join(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	entry, err := h.service.Join(r.Context(), id, input)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusCreated, entry); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *QueueController) CallNext(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	entry, err := h.service.CallNext(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusCreated, entry); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *QueueController) NoShow(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	entryId, err := strconv.Atoi(queryParams["entry_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.NoShow(r.Context(), id, entryId); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
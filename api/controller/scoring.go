@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"standmaster/api/middleware"
+	"standmaster/internal/models"
+	"standmaster/internal/scoring"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/json"
+)
+
+type ScoringController struct {
+	service        scoring.ScoringService
+	userRepository user.UserRepository
+}
+
+func NewScoringController(service scoring.ScoringService, userRepository user.UserRepository) *ScoringController {
+	return &ScoringController{
+		service:        service,
+		userRepository: userRepository,
+	}
+}
+
+func (h *ScoringController) RegisterRoutes(mux *mux.Router) {
+	mux.Handle("/kermesse/{id}/stand/{stand_id}/scoring", errors.ErrorHandler(middleware.IsAuth(h.Get, h.userRepository))).Methods(http.MethodGet)
+	mux.Handle("/kermesse/{id}/stand/{stand_id}/scoring", errors.ErrorHandler(middleware.IsAuth(h.Save, h.userRepository, models.UserRoleOrganizer))).Methods(http.MethodPatch)
+	mux.Handle("/kermesse/{id}/stand/{stand_id}/scoring", errors.ErrorHandler(middleware.IsAuth(h.Delete, h.userRepository, models.UserRoleOrganizer))).Methods(http.MethodDelete)
+}
+
+func (h *ScoringController) Get(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	standId, err := strconv.Atoi(queryParams["stand_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	rule, err := h.service.Get(r.Context(), id, standId)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, rule); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *ScoringController) Save(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	standId, err := strconv.Atoi(queryParams["stand_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Save(r.Context(), id, standId, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *ScoringController) Delete(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	standId, err := strconv.Atoi(queryParams["stand_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Delete(r.Context(), id, standId); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
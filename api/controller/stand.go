@@ -32,6 +32,15 @@ func (h *StandController) RegisterRoutes(mux *mux.Router) {
 	mux.Handle("/stand/{id}", errors.ErrorHandler(middleware.IsAuth(h.Get, h.userRepository))).Methods(http.MethodGet)
 	mux.Handle("/stand", errors.ErrorHandler(middleware.IsAuth(h.Create, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPost)
 	mux.Handle("/stand", errors.ErrorHandler(middleware.IsAuth(h.Update, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPatch)
+	mux.Handle("/stand/{id}/members", errors.ErrorHandler(middleware.IsAuth(h.GetMembers, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodGet)
+	mux.Handle("/stand/{id}/members", errors.ErrorHandler(middleware.IsAuth(h.AddMember, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPost)
+	mux.Handle("/stand/{id}/members/{user_id}", errors.ErrorHandler(middleware.IsAuth(h.RemoveMember, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodDelete)
+	mux.Handle("/stand/{id}/image", errors.ErrorHandler(middleware.IsAuth(h.UploadImage, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPost)
+	mux.Handle("/stand/{id}/tags", errors.ErrorHandler(middleware.IsAuth(h.SetTags, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPatch)
+	mux.Handle("/stand/{id}/pause", errors.ErrorHandler(middleware.IsAuth(h.Pause, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPatch)
+	mux.Handle("/stand/{id}/schedules", errors.ErrorHandler(middleware.IsAuth(h.GetSchedules, h.userRepository))).Methods(http.MethodGet)
+	mux.Handle("/stand/{id}/schedules", errors.ErrorHandler(middleware.IsAuth(h.CreateSchedule, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodPost)
+	mux.Handle("/stand/{id}/schedules/{schedule_id}", errors.ErrorHandler(middleware.IsAuth(h.DeleteSchedule, h.userRepository, models.UserRoleStandHolder))).Methods(http.MethodDelete)
 }
 
 func (h *StandController) GetAll(w http.ResponseWriter, r *http.Request) error {
@@ -136,3 +145,281 @@ func (h *StandController) Update(w http.ResponseWriter, r *http.Request) error {
 
 	return nil
 }
+
+func (h *StandController) GetMembers(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	members, err := h.service.GetMembers(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, members); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *StandController) AddMember(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.AddMember(r.Context(), id, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusCreated, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *StandController) RemoveMember(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	userId, err := strconv.Atoi(queryParams["user_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.RemoveMember(r.Context(), id, userId); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *StandController) Pause(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.Pause(r.Context(), id, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *StandController) SetTags(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.SetTags(r.Context(), id, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *StandController) GetSchedules(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	kermesseId, err := strconv.Atoi(r.URL.Query().Get("kermesse_id"))
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+
+	schedules, err := h.service.GetSchedules(r.Context(), id, kermesseId)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, schedules); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *StandController) CreateSchedule(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.CreateSchedule(r.Context(), id, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusCreated, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *StandController) DeleteSchedule(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	scheduleId, err := strconv.Atoi(queryParams["schedule_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.DeleteSchedule(r.Context(), id, scheduleId); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *StandController) UploadImage(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	file, err := formFile(w, r, "image")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stand, err := h.service.UploadImage(r.Context(), id, file)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, stand); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"standmaster/api/middleware"
+	"standmaster/internal/models"
+	"standmaster/internal/tag"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/json"
+)
+
+type TagController struct {
+	service        tag.TagService
+	userRepository user.UserRepository
+}
+
+func NewTagController(service tag.TagService, userRepository user.UserRepository) *TagController {
+	return &TagController{
+		service:        service,
+		userRepository: userRepository,
+	}
+}
+
+func (h *TagController) RegisterRoutes(mux *mux.Router) {
+	mux.Handle("/tags", errors.ErrorHandler(middleware.IsAuth(h.GetAll, h.userRepository))).Methods(http.MethodGet)
+	mux.Handle("/user/children/{child_id}/allergies", errors.ErrorHandler(middleware.IsAuth(h.GetAllergies, h.userRepository, models.UserRoleParent))).Methods(http.MethodGet)
+	mux.Handle("/user/children/{child_id}/allergies", errors.ErrorHandler(middleware.IsAuth(h.SetAllergies, h.userRepository, models.UserRoleParent))).Methods(http.MethodPatch)
+}
+
+func (h *TagController) GetAll(w http.ResponseWriter, r *http.Request) error {
+	tags, err := h.service.GetAll(r.Context())
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, tags); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *TagController) GetAllergies(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	childId, err := strconv.Atoi(queryParams["child_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	tags, err := h.service.GetAllergies(r.Context(), childId)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, tags); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (h *TagController) SetAllergies(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	childId, err := strconv.Atoi(queryParams["child_id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := h.service.SetAllergies(r.Context(), childId, input); err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusAccepted, nil); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"standmaster/api/middleware"
+	"standmaster/internal/models"
+	"standmaster/internal/topup"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/json"
+)
+
+type TopUpController struct {
+	service        topup.TopUpService
+	userRepository user.UserRepository
+}
+
+func NewTopUpController(service topup.TopUpService, userRepository user.UserRepository) *TopUpController {
+	return &TopUpController{
+		service:        service,
+		userRepository: userRepository,
+	}
+}
+
+func (h *TopUpController) RegisterRoutes(mux *mux.Router) {
+	mux.Handle("/user/topup", errors.ErrorHandler(middleware.IsAuth(h.Create, h.userRepository, models.UserRoleParent))).Methods(http.MethodPost)
+}
+
+func (h *TopUpController) Create(w http.ResponseWriter, r *http.Request) error {
+	var input map[string]interface{}
+	if err := json.Parse(r, &input); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	session, err := h.service.Create(r.Context(), input)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusCreated, session); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
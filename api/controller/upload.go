@@ -0,0 +1,35 @@
+package controller
+
+import (
+	goErrors "errors"
+	"mime/multipart"
+	"net/http"
+
+	"standmaster/pkg/errors"
+)
+
+// maxUploadRequestSize bounds the multipart body of uploads, the size of the
+// file itself is checked by the media service.
+const maxUploadRequestSize = 32 << 20
+
+// formFile returns the file sent in the key field of a multipart request.
+func formFile(w http.ResponseWriter, r *http.Request, key string) (multipart.File, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadRequestSize)
+
+	file, _, err := r.FormFile(key)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if goErrors.As(err, &maxBytesError) {
+			return nil, errors.CustomError{
+				Key: errors.FileTooLarge,
+				Err: err,
+			}
+		}
+		return nil, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+
+	return file, nil
+}
@@ -29,6 +29,7 @@ func (h *UserController) RegisterRoutes(mux *mux.Router) {
 	mux.Handle("/users", errors.ErrorHandler(middleware.IsAuth(h.GetAll, h.repository))).Methods(http.MethodGet)
 	mux.Handle("/user/children", errors.ErrorHandler(middleware.IsAuth(h.GetAllChildren, h.repository, models.UserRoleParent))).Methods(http.MethodGet)
 	mux.Handle("/user/{id}", errors.ErrorHandler(middleware.IsAuth(h.Get, h.repository))).Methods(http.MethodGet)
+	mux.Handle("/user/{id}/transactions", errors.ErrorHandler(middleware.IsAuth(h.GetTransactions, h.repository))).Methods(http.MethodGet)
 	mux.Handle("/user/invite", errors.ErrorHandler(middleware.IsAuth(h.Invite, h.repository, models.UserRoleParent))).Methods(http.MethodPost)
 	mux.Handle("/user/pay", errors.ErrorHandler(middleware.IsAuth(h.Pay, h.repository, models.UserRoleParent))).Methods(http.MethodPatch)
 	mux.Handle("/user/{id}", errors.ErrorHandler(middleware.IsAuth(h.Update, h.repository))).Methods(http.MethodPatch)
@@ -95,6 +96,31 @@ func (h *UserController) Get(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+func (h *UserController) GetTransactions(w http.ResponseWriter, r *http.Request) error {
+	queryParams := mux.Vars(r)
+	id, err := strconv.Atoi(queryParams["id"])
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	transactions, err := h.service.GetTransactions(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Write(w, http.StatusOK, transactions); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
 func (h *UserController) Update(w http.ResponseWriter, r *http.Request) error {
 	queryParams := mux.Vars(r)
 	id, err := strconv.Atoi(queryParams["id"])
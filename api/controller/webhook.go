@@ -1,20 +1,15 @@
 package controller
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
-	"os"
-	"strconv"
 
-	"github.com/stripe/stripe-go"
-	"github.com/stripe/stripe-go/webhook"
-	"standmaster/internal/user"
+	"standmaster/internal/webhook"
+	"standmaster/third_party/payment"
 )
 
-func HandleWebhook(userService user.UserService) http.HandlerFunc {
+func HandleWebhook(webhookService webhook.WebhookService, paymentProvider payment.Provider) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const MaxBodyBytes = int64(65536)
 		r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
@@ -24,50 +19,21 @@ func HandleWebhook(userService user.UserService) http.HandlerFunc {
 			return
 		}
 
-		signatureHeader := r.Header.Get("Stripe-Signature")
-		event, err := webhook.ConstructEvent(payload, signatureHeader, os.Getenv("STRIPE_WEBHOOK_SECRET"))
-		if err != nil {
+		if err := paymentProvider.VerifyWebhook(payload, r.Header); err != nil {
 			http.Error(w, fmt.Sprintf("Webhook signature verification failed: %v", err), http.StatusBadRequest)
 			return
 		}
 
-		if event.Type == "checkout.session.completed" {
-			var session stripe.CheckoutSession
-			if err := json.Unmarshal(event.Data.Raw, &session); err != nil {
-				http.Error(w, "Webhook Error", http.StatusBadRequest)
-				return
-			}
-
-			creditStr, ok := session.Metadata["credit"]
-			if !ok {
-				http.Error(w, "Invalid credit", http.StatusBadRequest)
-				return
-			}
-			credit, err := strconv.Atoi(creditStr)
-			if err != nil {
-				http.Error(w, "Invalid credit", http.StatusBadRequest)
-				return
-			}
-
-			userIdStr, ok := session.Metadata["user_id"]
-			if !ok {
-				http.Error(w, "Invalid user id", http.StatusBadRequest)
-				return
-			}
-			userId, err := strconv.Atoi(userIdStr)
-			if err != nil {
-				http.Error(w, "Invalid user id", http.StatusBadRequest)
-				return
-			}
+		event, err := paymentProvider.ParseEvent(payload)
+		if err != nil {
+			http.Error(w, "Webhook Error", http.StatusBadRequest)
+			return
+		}
 
-			err = userService.UpdateCredit(userId, credit)
-			log.Printf("EROOOOOOOR: %v\n", err)
-			if err != nil {
-				http.Error(w, "Error updating user credit", http.StatusInternalServerError)
-				return
-			}
-		} else {
-			fmt.Printf("Unhandled event type: %s\n", event.Type)
+		// replays of an event already handled are acknowledged without side effects
+		if err := webhookService.Handle(event, payload); err != nil {
+			http.Error(w, "Error handling webhook event", http.StatusInternalServerError)
+			return
 		}
 
 		w.WriteHeader(http.StatusOK)
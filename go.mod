@@ -10,6 +10,7 @@ require (
 	github.com/lib/pq v1.10.9
 	github.com/resend/resend-go/v2 v2.12.0
 	github.com/rs/cors v1.11.1
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/stripe/stripe-go v70.15.0+incompatible
 	golang.org/x/crypto v0.27.0
 )
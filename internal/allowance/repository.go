@@ -0,0 +1,115 @@
+package allowance
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+	"standmaster/third_party/database"
+)
+
+type AllowanceRepository interface {
+	WithTx(tx *sqlx.Tx) AllowanceRepository
+	FindAllByChildId(childId int) ([]models.Allowance, error)
+	FindAllDueIds() ([]int, error)
+	FindById(id int) (models.Allowance, error)
+	FindByIdForUpdate(id int) (models.Allowance, error)
+	Create(input map[string]interface{}) error
+	Update(id int, input map[string]interface{}) error
+	Schedule(id int, nextRunAt time.Time, isActive bool) error
+
+	FindAllRuns(id int) ([]models.AllowanceRun, error)
+	CreateRun(input map[string]interface{}) error
+}
+
+type Repository struct {
+	db database.Queryer
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) WithTx(tx *sqlx.Tx) AllowanceRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
+func (s *Repository) FindAllByChildId(childId int) ([]models.Allowance, error) {
+	allowances := []models.Allowance{}
+	query := "SELECT * FROM allowances WHERE child_id=$1 ORDER BY id"
+	err := s.db.Select(&allowances, query, childId)
+
+	return allowances, err
+}
+
+// FindAllDueIds returns the active allowances to pay now, only allowances of
+// running kermesses are paid.
+func (s *Repository) FindAllDueIds() ([]int, error) {
+	ids := []int{}
+	query := `
+		SELECT a.id
+		FROM allowances a
+		JOIN kermesses k ON a.kermesse_id = k.id
+		WHERE a.is_active AND a.next_run_at <= CURRENT_TIMESTAMP AND k.status = $1
+		ORDER BY a.next_run_at, a.id
+	`
+	err := s.db.Select(&ids, query, models.KermesseStatusRunning)
+
+	return ids, err
+}
+
+func (s *Repository) FindById(id int) (models.Allowance, error) {
+	allowance := models.Allowance{}
+	query := "SELECT * FROM allowances WHERE id=$1"
+	err := s.db.Get(&allowance, query, id)
+
+	return allowance, err
+}
+
+func (s *Repository) FindByIdForUpdate(id int) (models.Allowance, error) {
+	allowance := models.Allowance{}
+	query := "SELECT * FROM allowances WHERE id=$1 FOR UPDATE"
+	err := s.db.Get(&allowance, query, id)
+
+	return allowance, err
+}
+
+func (s *Repository) Create(input map[string]interface{}) error {
+	query := "INSERT INTO allowances (parent_id, child_id, kermesse_id, type, amount, interval_minutes, next_run_at) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	_, err := s.db.Exec(query, input["parent_id"], input["child_id"], input["kermesse_id"], input["type"], input["amount"], input["interval_minutes"], input["next_run_at"])
+
+	return err
+}
+
+func (s *Repository) Update(id int, input map[string]interface{}) error {
+	query := "UPDATE allowances SET amount=$1, interval_minutes=$2, is_active=$3, next_run_at=$4 WHERE id=$5"
+	_, err := s.db.Exec(query, input["amount"], input["interval_minutes"], input["is_active"], input["next_run_at"], id)
+
+	return err
+}
+
+func (s *Repository) Schedule(id int, nextRunAt time.Time, isActive bool) error {
+	query := "UPDATE allowances SET next_run_at=$1, is_active=$2 WHERE id=$3"
+	_, err := s.db.Exec(query, nextRunAt, isActive, id)
+
+	return err
+}
+
+func (s *Repository) FindAllRuns(id int) ([]models.AllowanceRun, error) {
+	runs := []models.AllowanceRun{}
+	query := "SELECT * FROM allowance_runs WHERE allowance_id=$1 ORDER BY created_at DESC, id DESC"
+	err := s.db.Select(&runs, query, id)
+
+	return runs, err
+}
+
+func (s *Repository) CreateRun(input map[string]interface{}) error {
+	query := "INSERT INTO allowance_runs (allowance_id, amount, status, error) VALUES ($1, $2, $3, $4)"
+	_, err := s.db.Exec(query, input["allowance_id"], input["amount"], input["status"], input["error"])
+
+	return err
+}
@@ -0,0 +1,436 @@
+package allowance
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/kermesse"
+	"standmaster/internal/ledger"
+	"standmaster/internal/models"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/utils"
+	"standmaster/third_party/database"
+)
+
+type AllowanceService interface {
+	GetAll(ctx context.Context, childId int) ([]models.Allowance, error)
+	GetRuns(ctx context.Context, childId int, id int) ([]models.AllowanceRun, error)
+	Create(ctx context.Context, childId int, input map[string]interface{}) error
+	Update(ctx context.Context, childId int, id int, input map[string]interface{}) error
+	Delete(ctx context.Context, childId int, id int) error
+	RunDue() error
+}
+
+type Service struct {
+	repository         AllowanceRepository
+	userRepository     user.UserRepository
+	kermesseRepository kermesse.KermesseRepository
+	ledgerRepository   ledger.LedgerRepository
+	transactor         database.Transactor
+}
+
+func NewService(repository AllowanceRepository, userRepository user.UserRepository, kermesseRepository kermesse.KermesseRepository, ledgerRepository ledger.LedgerRepository, transactor database.Transactor) *Service {
+	return &Service{
+		repository:         repository,
+		userRepository:     userRepository,
+		kermesseRepository: kermesseRepository,
+		ledgerRepository:   ledgerRepository,
+		transactor:         transactor,
+	}
+}
+
+func (s *Service) GetAll(ctx context.Context, childId int) ([]models.Allowance, error) {
+	if _, err := s.checkParent(ctx, childId); err != nil {
+		return nil, err
+	}
+
+	allowances, err := s.repository.FindAllByChildId(childId)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return allowances, nil
+}
+
+func (s *Service) GetRuns(ctx context.Context, childId int, id int) ([]models.AllowanceRun, error) {
+	if _, err := s.findAllowance(ctx, childId, id); err != nil {
+		return nil, err
+	}
+
+	runs, err := s.repository.FindAllRuns(id)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return runs, nil
+}
+
+func (s *Service) Create(ctx context.Context, childId int, input map[string]interface{}) error {
+	parentId, err := s.checkParent(ctx, childId)
+	if err != nil {
+		return err
+	}
+
+	kermesseId, err := utils.GetIntFromMap(input, "kermesse_id")
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+	if _, err := s.kermesseRepository.FindById(kermesseId); err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	hasUser, err := s.kermesseRepository.HasUser(kermesseId, childId)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if !hasUser {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("child is not part of the kermesse"),
+		}
+	}
+
+	amount, err := parseAmount(input)
+	if err != nil {
+		return err
+	}
+
+	// a lump sum is paid as soon as the kermesse is started, an interval
+	// allowance one interval after its creation
+	allowanceType, _ := input["type"].(string)
+	var intervalMinutes *int
+	nextRunAt := time.Now()
+	switch allowanceType {
+	case models.AllowanceTypeKermesseStart:
+	case models.AllowanceTypeInterval:
+		minutes, err := parseInterval(input)
+		if err != nil {
+			return err
+		}
+		intervalMinutes = &minutes
+		nextRunAt = nextRunAt.Add(time.Duration(minutes) * time.Minute)
+	default:
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("invalid allowance type"),
+		}
+	}
+
+	err = s.repository.Create(map[string]interface{}{
+		"parent_id":        parentId,
+		"child_id":         childId,
+		"kermesse_id":      kermesseId,
+		"type":             allowanceType,
+		"amount":           amount,
+		"interval_minutes": intervalMinutes,
+		"next_run_at":      nextRunAt,
+	})
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// Update changes the amount, the interval or pauses an allowance, changing
+// the interval restarts it from now.
+func (s *Service) Update(ctx context.Context, childId int, id int, input map[string]interface{}) error {
+	allowance, err := s.findAllowance(ctx, childId, id)
+	if err != nil {
+		return err
+	}
+
+	if input["amount"] != nil {
+		allowance.Amount, err = parseAmount(input)
+		if err != nil {
+			return err
+		}
+	}
+
+	if input["interval_minutes"] != nil {
+		if allowance.Type != models.AllowanceTypeInterval {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("allowance has no interval"),
+			}
+		}
+		minutes, err := parseInterval(input)
+		if err != nil {
+			return err
+		}
+		allowance.IntervalMinutes = &minutes
+		allowance.NextRunAt = time.Now().Add(time.Duration(minutes) * time.Minute)
+	}
+
+	if input["is_active"] != nil {
+		isActive, ok := input["is_active"].(bool)
+		if !ok {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("is_active is not a valid boolean"),
+			}
+		}
+		allowance.IsActive = isActive
+	}
+
+	err = s.repository.Update(id, map[string]interface{}{
+		"amount":           allowance.Amount,
+		"interval_minutes": allowance.IntervalMinutes,
+		"is_active":        allowance.IsActive,
+		"next_run_at":      allowance.NextRunAt,
+	})
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// Delete stops the allowance, it is kept so its runs stay recorded.
+func (s *Service) Delete(ctx context.Context, childId int, id int) error {
+	allowance, err := s.findAllowance(ctx, childId, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.Schedule(id, allowance.NextRunAt, false)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// RunDue pays the allowances due now, it's run by the scheduler. An allowance
+// that can't be paid doesn't hold back the others.
+func (s *Service) RunDue() error {
+	ids, err := s.repository.FindAllDueIds()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := s.pay(id); err != nil {
+			log.Printf("Error running allowance %d: %v\n", id, err)
+		}
+	}
+
+	return nil
+}
+
+// pay reschedules a due allowance, transfers its amount and records the run
+// in one transaction. A failing transfer, for lack of parent credit for
+// instance, is rolled back then recorded once as a FAILED run and retried at
+// the next interval.
+func (s *Service) pay(id int) error {
+	var failure error
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		allowance, isDue, err := s.reschedule(tx, id)
+		if err != nil || !isDue {
+			return err
+		}
+
+		if err := s.transfer(tx, allowance); err != nil {
+			failure = err
+			return err
+		}
+
+		return s.repository.WithTx(tx).CreateRun(map[string]interface{}{
+			"allowance_id": allowance.Id,
+			"amount":       allowance.Amount,
+			"status":       models.AllowanceRunStatusSucceeded,
+		})
+	})
+	if failure == nil {
+		return err
+	}
+
+	log.Printf("Error paying allowance %d: %v\n", id, failure)
+	return s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		allowance, isDue, err := s.reschedule(tx, id)
+		if err != nil || !isDue {
+			return err
+		}
+
+		return s.repository.WithTx(tx).CreateRun(map[string]interface{}{
+			"allowance_id": allowance.Id,
+			"amount":       allowance.Amount,
+			"status":       models.AllowanceRunStatusFailed,
+			"error":        failure.Error(),
+		})
+	})
+}
+
+// reschedule locks a due allowance and moves it to its next run, or
+// deactivates it when it's only paid once. false is returned when the
+// allowance isn't due anymore.
+func (s *Service) reschedule(tx *sqlx.Tx, id int) (models.Allowance, bool, error) {
+	repository := s.repository.WithTx(tx)
+
+	allowance, err := repository.FindByIdForUpdate(id)
+	if err != nil {
+		return allowance, false, err
+	}
+
+	now := time.Now()
+	if !allowance.IsActive || allowance.NextRunAt.After(now) {
+		return allowance, false, nil
+	}
+
+	if allowance.Type == models.AllowanceTypeKermesseStart {
+		return allowance, true, repository.Schedule(id, allowance.NextRunAt, false)
+	}
+
+	// runs missed while the kermesse was stopped aren't caught up
+	return allowance, true, repository.Schedule(id, now.Add(time.Duration(*allowance.IntervalMinutes)*time.Minute), true)
+}
+
+// transfer moves the amount of the allowance from the parent to the child.
+func (s *Service) transfer(tx *sqlx.Tx, allowance models.Allowance) error {
+	parent, err := s.userRepository.WithTx(tx).FindByIdForUpdate(allowance.ParentId)
+	if err != nil {
+		return err
+	}
+	if parent.Credit < allowance.Amount {
+		return goErrors.New("insufficient credit")
+	}
+
+	return s.ledgerRepository.WithTx(tx).Create(map[string]interface{}{
+		"debit_user_id":  allowance.ParentId,
+		"credit_user_id": allowance.ChildId,
+		"amount":         allowance.Amount,
+		"kind":           models.CreditTransactionKindTransfer,
+	})
+}
+
+// checkParent makes sure the user of ctx is the parent of the child and
+// returns their id.
+func (s *Service) checkParent(ctx context.Context, childId int) (int, error) {
+	parentId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return 0, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	child, err := s.userRepository.FindById(childId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return 0, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return 0, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if child.ParentId == nil || *child.ParentId != parentId {
+		return 0, errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("forbidden"),
+		}
+	}
+
+	return parentId, nil
+}
+
+func (s *Service) findAllowance(ctx context.Context, childId int, id int) (models.Allowance, error) {
+	if _, err := s.checkParent(ctx, childId); err != nil {
+		return models.Allowance{}, err
+	}
+
+	allowance, err := s.repository.FindById(id)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return models.Allowance{}, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return models.Allowance{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if allowance.ChildId != childId {
+		return models.Allowance{}, errors.CustomError{
+			Key: errors.NotFound,
+			Err: goErrors.New("allowance not found"),
+		}
+	}
+
+	return allowance, nil
+}
+
+func parseAmount(input map[string]interface{}) (int, error) {
+	amount, err := utils.GetIntFromMap(input, "amount")
+	if err != nil {
+		return 0, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+	if amount <= 0 {
+		return 0, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("amount must be positive"),
+		}
+	}
+
+	return amount, nil
+}
+
+func parseInterval(input map[string]interface{}) (int, error) {
+	minutes, err := utils.GetIntFromMap(input, "interval_minutes")
+	if err != nil {
+		return 0, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+	if minutes <= 0 {
+		return 0, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("interval_minutes must be positive"),
+		}
+	}
+
+	return minutes, nil
+}
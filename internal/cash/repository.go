@@ -0,0 +1,61 @@
+package cash
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+	"standmaster/third_party/database"
+)
+
+type CashRepository interface {
+	WithTx(tx *sqlx.Tx) CashRepository
+	FindAllByKermesseId(kermesseId int, from time.Time, to time.Time) ([]models.CashTopUp, error)
+	ExistsByReceiptNumber(kermesseId int, receiptNumber string) (bool, error)
+	Create(input map[string]interface{}) (int, error)
+}
+
+type Repository struct {
+	db database.Queryer
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) WithTx(tx *sqlx.Tx) CashRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
+func (s *Repository) FindAllByKermesseId(kermesseId int, from time.Time, to time.Time) ([]models.CashTopUp, error) {
+	topUps := []models.CashTopUp{}
+	query := `
+		SELECT *
+		FROM cash_topups
+		WHERE kermesse_id=$1 AND created_at >= $2 AND created_at < $3
+		ORDER BY operator_id, created_at, id
+	`
+	err := s.db.Select(&topUps, query, kermesseId, from, to)
+
+	return topUps, err
+}
+
+func (s *Repository) ExistsByReceiptNumber(kermesseId int, receiptNumber string) (bool, error) {
+	var isTrue bool
+	query := "SELECT EXISTS ( SELECT 1 FROM cash_topups WHERE kermesse_id = $1 AND receipt_number = $2 ) AS is_true"
+	err := s.db.QueryRow(query, kermesseId, receiptNumber).Scan(&isTrue)
+
+	return isTrue, err
+}
+
+func (s *Repository) Create(input map[string]interface{}) (int, error) {
+	var id int
+	query := "INSERT INTO cash_topups (kermesse_id, user_id, operator_id, amount, credit, receipt_number) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+	err := s.db.QueryRow(query, input["kermesse_id"], input["user_id"], input["operator_id"], input["amount"], input["credit"], input["receipt_number"]).Scan(&id)
+
+	return id, err
+}
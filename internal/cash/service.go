@@ -0,0 +1,295 @@
+package cash
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/kermesse"
+	"standmaster/internal/ledger"
+	"standmaster/internal/models"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/utils"
+	"standmaster/third_party/database"
+)
+
+type CashService interface {
+	Create(ctx context.Context, kermesseId int, input map[string]interface{}) (models.CashTopUp, error)
+	Report(ctx context.Context, kermesseId int, date string) ([]models.CashReport, error)
+}
+
+type Service struct {
+	repository         CashRepository
+	kermesseRepository kermesse.KermesseRepository
+	userRepository     user.UserRepository
+	ledgerRepository   ledger.LedgerRepository
+	transactor         database.Transactor
+}
+
+func NewService(repository CashRepository, kermesseRepository kermesse.KermesseRepository, userRepository user.UserRepository, ledgerRepository ledger.LedgerRepository, transactor database.Transactor) *Service {
+	return &Service{
+		repository:         repository,
+		kermesseRepository: kermesseRepository,
+		userRepository:     userRepository,
+		ledgerRepository:   ledgerRepository,
+		transactor:         transactor,
+	}
+}
+
+// Create credits a parent or child of the kermesse with the cash handed to
+// the organizer, who is recorded as the operator of the top-up.
+func (s *Service) Create(ctx context.Context, kermesseId int, input map[string]interface{}) (models.CashTopUp, error) {
+	operatorId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return models.CashTopUp{}, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	kermesse, err := s.findKermesse(kermesseId, operatorId)
+	if err != nil {
+		return models.CashTopUp{}, err
+	}
+	// families can top up once the kermesse is published
+	if kermesse.Status != models.KermesseStatusPublished && kermesse.Status != models.KermesseStatusRunning {
+		return models.CashTopUp{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("kermesse is not open"),
+		}
+	}
+
+	userId, err := utils.GetIntFromMap(input, "user_id")
+	if err != nil {
+		return models.CashTopUp{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+
+	// amount is in euros
+	amount, err := utils.GetIntFromMap(input, "amount")
+	if err != nil {
+		return models.CashTopUp{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+	if amount <= 0 {
+		return models.CashTopUp{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("amount must be positive"),
+		}
+	}
+
+	receiptNumber, _ := input["receipt_number"].(string)
+	receiptNumber = strings.TrimSpace(receiptNumber)
+	if receiptNumber == "" {
+		return models.CashTopUp{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("receipt_number is missing"),
+		}
+	}
+
+	creditPerEuro, err := strconv.Atoi(os.Getenv("PAYMENT_CREDIT_PER_EURO"))
+	if err != nil {
+		return models.CashTopUp{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	topUp := models.CashTopUp{
+		KermesseId:    kermesseId,
+		UserId:        userId,
+		OperatorId:    operatorId,
+		Amount:        amount * 100,
+		Credit:        amount * creditPerEuro,
+		ReceiptNumber: receiptNumber,
+	}
+
+	err = s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+		userRepository := s.userRepository.WithTx(tx)
+		ledgerRepository := s.ledgerRepository.WithTx(tx)
+
+		user, err := userRepository.FindByIdForUpdate(userId)
+		if err != nil {
+			if goErrors.Is(err, sql.ErrNoRows) {
+				return errors.CustomError{
+					Key: errors.NotFound,
+					Err: err,
+				}
+			}
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		if user.Role != models.UserRoleParent && user.Role != models.UserRoleChild {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("user is not a parent or a child"),
+			}
+		}
+
+		hasUser, err := s.kermesseRepository.HasUser(kermesseId, userId)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		if !hasUser {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("user is not part of the kermesse"),
+			}
+		}
+
+		isUsed, err := repository.ExistsByReceiptNumber(kermesseId, receiptNumber)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		if isUsed {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("receipt number is already used"),
+			}
+		}
+
+		topUp.Id, err = repository.Create(map[string]interface{}{
+			"kermesse_id":    topUp.KermesseId,
+			"user_id":        topUp.UserId,
+			"operator_id":    topUp.OperatorId,
+			"amount":         topUp.Amount,
+			"credit":         topUp.Credit,
+			"receipt_number": topUp.ReceiptNumber,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		err = ledgerRepository.Create(map[string]interface{}{
+			"credit_user_id": topUp.UserId,
+			"amount":         topUp.Credit,
+			"kind":           models.CreditTransactionKindCashTopUp,
+			"cash_topup_id":  topUp.Id,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.CashTopUp{}, errors.FromError(err)
+	}
+	topUp.CreatedAt = time.Now()
+
+	return topUp, nil
+}
+
+// Report groups the cash top-ups of the kermesse taken on date (YYYY-MM-DD,
+// today when empty) by operator.
+func (s *Service) Report(ctx context.Context, kermesseId int, date string) ([]models.CashReport, error) {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return nil, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	if _, err := s.findKermesse(kermesseId, userId); err != nil {
+		return nil, err
+	}
+
+	from := time.Now()
+	if date != "" {
+		var err error
+		from, err = time.ParseInLocation(time.DateOnly, date, time.Local)
+		if err != nil {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 0, 1)
+
+	topUps, err := s.repository.FindAllByKermesseId(kermesseId, from, to)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	// top-ups come ordered by operator
+	reports := []models.CashReport{}
+	for _, topUp := range topUps {
+		if len(reports) == 0 || reports[len(reports)-1].OperatorId != topUp.OperatorId {
+			operator, err := s.userRepository.FindById(topUp.OperatorId)
+			if err != nil {
+				return nil, errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+			reports = append(reports, models.CashReport{
+				OperatorId:   operator.Id,
+				OperatorName: operator.Name,
+				TopUps:       []models.CashTopUp{},
+			})
+		}
+
+		report := &reports[len(reports)-1]
+		report.Count++
+		report.Amount += topUp.Amount
+		report.Credit += topUp.Credit
+		report.TopUps = append(report.TopUps, topUp)
+	}
+
+	return reports, nil
+}
+
+func (s *Service) findKermesse(id int, organizerId int) (models.Kermesse, error) {
+	kermesse, err := s.kermesseRepository.FindById(id)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return models.Kermesse{}, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return models.Kermesse{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if kermesse.UserId != organizerId {
+		return models.Kermesse{}, errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("forbidden"),
+		}
+	}
+
+	return kermesse, nil
+}
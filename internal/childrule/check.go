@@ -0,0 +1,112 @@
+package childrule
+
+import (
+	goErrors "errors"
+	"fmt"
+	"time"
+
+	"standmaster/internal/models"
+	"standmaster/pkg/errors"
+)
+
+// Purchase is what a child is about to spend at a given time, StandId and
+// StandType are empty for tombola tickets.
+type Purchase struct {
+	KermesseId int
+	StandId    int
+	StandType  string
+	Amount     int
+	At         time.Time
+}
+
+// Check returns an error keyed after the first rule of the child the
+// purchase breaks. Run it in the purchase transaction, after locking the
+// child, so concurrent purchases can't both fit under a limit.
+func Check(repository ChildRuleRepository, childId int, purchase Purchase) error {
+	rules, err := repository.FindAllByChildId(childId)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	for _, rule := range rules {
+		switch rule.Type {
+		case models.ChildRuleTypeBlockedStandType:
+			if purchase.StandType != "" && *rule.StandType == purchase.StandType {
+				return errors.CustomError{
+					Key: errors.StandBlocked,
+					Err: fmt.Errorf("%s stands are blocked", purchase.StandType),
+				}
+			}
+		case models.ChildRuleTypeBlockedStand:
+			if purchase.StandId != 0 && *rule.StandId == purchase.StandId {
+				return errors.CustomError{
+					Key: errors.StandBlocked,
+					Err: goErrors.New("stand is blocked"),
+				}
+			}
+		case models.ChildRuleTypeMaxPurchase:
+			if purchase.Amount > *rule.Value {
+				return errors.CustomError{
+					Key: errors.PurchaseLimitExceeded,
+					Err: fmt.Errorf("purchase is above %d credits", *rule.Value),
+				}
+			}
+		case models.ChildRuleTypeDailyLimit:
+			// the day of the purchase, an offline sale counts on the day it was made
+			at := purchase.At
+			from := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.Local)
+			spent, err := repository.SpentBetween(childId, from, from.AddDate(0, 0, 1))
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+			if spent+purchase.Amount > *rule.Value {
+				return errors.CustomError{
+					Key: errors.SpendingLimitExceeded,
+					Err: fmt.Errorf("daily limit of %d credits exceeded", *rule.Value),
+				}
+			}
+		case models.ChildRuleTypeKermesseLimit:
+			spent, err := repository.SpentInKermesse(childId, purchase.KermesseId)
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+			if spent+purchase.Amount > *rule.Value {
+				return errors.CustomError{
+					Key: errors.SpendingLimitExceeded,
+					Err: fmt.Errorf("kermesse limit of %d credits exceeded", *rule.Value),
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// NeedsApproval tells if the parent has to approve a purchase of amount
+// credits before it goes through.
+func NeedsApproval(repository ChildRuleRepository, childId int, amount int) (bool, error) {
+	rules, err := repository.FindAllByChildId(childId)
+	if err != nil {
+		return false, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Type == models.ChildRuleTypeApprovalThreshold && amount > *rule.Value {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,117 @@
+package childrule
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+	"standmaster/third_party/database"
+)
+
+type ChildRuleRepository interface {
+	WithTx(tx *sqlx.Tx) ChildRuleRepository
+	FindAllByChildId(childId int) ([]models.ChildRule, error)
+	FindById(id int) (models.ChildRule, error)
+	Create(input map[string]interface{}) error
+	Update(id int, input map[string]interface{}) error
+	Delete(id int) error
+	SpentBetween(childId int, from time.Time, to time.Time) (int, error)
+	SpentInKermesse(childId int, kermesseId int) (int, error)
+}
+
+type Repository struct {
+	db database.Queryer
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) WithTx(tx *sqlx.Tx) ChildRuleRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
+func (s *Repository) FindAllByChildId(childId int) ([]models.ChildRule, error) {
+	rules := []models.ChildRule{}
+	query := "SELECT * FROM child_rules WHERE child_id=$1 ORDER BY id"
+	err := s.db.Select(&rules, query, childId)
+
+	return rules, err
+}
+
+func (s *Repository) FindById(id int) (models.ChildRule, error) {
+	rule := models.ChildRule{}
+	query := "SELECT * FROM child_rules WHERE id=$1"
+	err := s.db.Get(&rule, query, id)
+
+	return rule, err
+}
+
+func (s *Repository) Create(input map[string]interface{}) error {
+	query := "INSERT INTO child_rules (child_id, type, value, stand_type, stand_id) VALUES ($1, $2, $3, $4, $5)"
+	_, err := s.db.Exec(query, input["child_id"], input["type"], input["value"], input["stand_type"], input["stand_id"])
+
+	return err
+}
+
+func (s *Repository) Update(id int, input map[string]interface{}) error {
+	query := "UPDATE child_rules SET value=$1, stand_type=$2, stand_id=$3 WHERE id=$4"
+	_, err := s.db.Exec(query, input["value"], input["stand_type"], input["stand_id"], id)
+
+	return err
+}
+
+func (s *Repository) Delete(id int) error {
+	query := "DELETE FROM child_rules WHERE id=$1"
+	_, err := s.db.Exec(query, id)
+
+	return err
+}
+
+// SpentBetween sums what the child spent on interactions, net of refunds and
+// including the ones awaiting approval, and tickets between from and to.
+func (s *Repository) SpentBetween(childId int, from time.Time, to time.Time) (int, error) {
+	var spent int
+	query := `
+		SELECT
+			(
+				SELECT COALESCE(SUM(i.credit - i.credit * i.refunded_quantity / i.quantity), 0)
+				FROM interactions i
+				WHERE i.user_id = $1 AND i.created_at >= $2 AND i.created_at < $3 AND i.status NOT IN ($4, $5)
+			) + (
+				SELECT COALESCE(SUM(tb.price), 0)
+				FROM tickets t
+				JOIN tombolas tb ON t.tombola_id = tb.id
+				WHERE t.user_id = $1 AND t.created_at >= $2 AND t.created_at < $3
+			)
+	`
+	err := s.db.Get(&spent, query, childId, from, to, models.InteractionStatusRejected, models.InteractionStatusExpired)
+
+	return spent, err
+}
+
+// SpentInKermesse sums what the child spent on interactions, net of refunds
+// and including the ones awaiting approval, and tickets in the kermesse.
+func (s *Repository) SpentInKermesse(childId int, kermesseId int) (int, error) {
+	var spent int
+	query := `
+		SELECT
+			(
+				SELECT COALESCE(SUM(i.credit - i.credit * i.refunded_quantity / i.quantity), 0)
+				FROM interactions i
+				WHERE i.user_id = $1 AND i.kermesse_id = $2 AND i.status NOT IN ($3, $4)
+			) + (
+				SELECT COALESCE(SUM(tb.price), 0)
+				FROM tickets t
+				JOIN tombolas tb ON t.tombola_id = tb.id
+				WHERE t.user_id = $1 AND tb.kermesse_id = $2
+			)
+	`
+	err := s.db.Get(&spent, query, childId, kermesseId, models.InteractionStatusRejected, models.InteractionStatusExpired)
+
+	return spent, err
+}
@@ -0,0 +1,239 @@
+package childrule
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+
+	"standmaster/internal/models"
+	"standmaster/internal/stand"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/utils"
+)
+
+type ChildRuleService interface {
+	GetAll(ctx context.Context, childId int) ([]models.ChildRule, error)
+	Create(ctx context.Context, childId int, input map[string]interface{}) error
+	Update(ctx context.Context, childId int, id int, input map[string]interface{}) error
+	Delete(ctx context.Context, childId int, id int) error
+}
+
+type Service struct {
+	repository      ChildRuleRepository
+	userRepository  user.UserRepository
+	standRepository stand.StandRepository
+}
+
+func NewService(repository ChildRuleRepository, userRepository user.UserRepository, standRepository stand.StandRepository) *Service {
+	return &Service{
+		repository:      repository,
+		userRepository:  userRepository,
+		standRepository: standRepository,
+	}
+}
+
+func (s *Service) GetAll(ctx context.Context, childId int) ([]models.ChildRule, error) {
+	if err := s.checkParent(ctx, childId); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.repository.FindAllByChildId(childId)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return rules, nil
+}
+
+func (s *Service) Create(ctx context.Context, childId int, input map[string]interface{}) error {
+	if err := s.checkParent(ctx, childId); err != nil {
+		return err
+	}
+
+	ruleType, _ := input["type"].(string)
+	values, err := s.parse(ruleType, input)
+	if err != nil {
+		return err
+	}
+	values["child_id"] = childId
+	values["type"] = ruleType
+
+	err = s.repository.Create(values)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// Update changes the settings of a rule, its type can't be changed.
+func (s *Service) Update(ctx context.Context, childId int, id int, input map[string]interface{}) error {
+	rule, err := s.findRule(ctx, childId, id)
+	if err != nil {
+		return err
+	}
+
+	values, err := s.parse(rule.Type, input)
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.Update(id, values)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) Delete(ctx context.Context, childId int, id int) error {
+	if _, err := s.findRule(ctx, childId, id); err != nil {
+		return err
+	}
+
+	err := s.repository.Delete(id)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// checkParent makes sure the user of ctx is the parent of the child.
+func (s *Service) checkParent(ctx context.Context, childId int) error {
+	parentId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	child, err := s.userRepository.FindById(childId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if child.ParentId == nil || *child.ParentId != parentId {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("forbidden"),
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) findRule(ctx context.Context, childId int, id int) (models.ChildRule, error) {
+	if err := s.checkParent(ctx, childId); err != nil {
+		return models.ChildRule{}, err
+	}
+
+	rule, err := s.repository.FindById(id)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return models.ChildRule{}, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return models.ChildRule{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if rule.ChildId != childId {
+		return models.ChildRule{}, errors.CustomError{
+			Key: errors.NotFound,
+			Err: goErrors.New("rule not found"),
+		}
+	}
+
+	return rule, nil
+}
+
+// parse validates the settings a rule of ruleType needs and returns them as
+// the repository expects them.
+func (s *Service) parse(ruleType string, input map[string]interface{}) (map[string]interface{}, error) {
+	values := map[string]interface{}{
+		"value":      nil,
+		"stand_type": nil,
+		"stand_id":   nil,
+	}
+
+	switch ruleType {
+	case models.ChildRuleTypeDailyLimit, models.ChildRuleTypeKermesseLimit, models.ChildRuleTypeMaxPurchase, models.ChildRuleTypeApprovalThreshold:
+		value, err := utils.GetIntFromMap(input, "value")
+		if err != nil {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		if value < 0 {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("value can't be negative"),
+			}
+		}
+		values["value"] = value
+	case models.ChildRuleTypeBlockedStandType:
+		standType, _ := input["stand_type"].(string)
+		if standType != models.StandTypeBuyer && standType != models.StandTypeActivity {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("invalid stand type"),
+			}
+		}
+		values["stand_type"] = standType
+	case models.ChildRuleTypeBlockedStand:
+		standId, err := utils.GetIntFromMap(input, "stand_id")
+		if err != nil {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		if _, err := s.standRepository.FindById(standId); err != nil {
+			if goErrors.Is(err, sql.ErrNoRows) {
+				return nil, errors.CustomError{
+					Key: errors.NotFound,
+					Err: err,
+				}
+			}
+			return nil, errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		values["stand_id"] = standId
+	default:
+		return nil, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("invalid rule type"),
+		}
+	}
+
+	return values, nil
+}
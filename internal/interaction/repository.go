@@ -4,19 +4,29 @@ import (
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"standmaster/internal/models"
+	"standmaster/third_party/database"
 )
 
 type InteractionRepository interface {
+	WithTx(tx *sqlx.Tx) InteractionRepository
 	FindAll(filters map[string]interface{}) ([]models.InteractionBasic, error)
 	FindById(id int) (models.Interaction, error)
+	FindByIdForUpdate(id int) (models.Interaction, error)
+	FindAllExpiredIds() ([]int, error)
 	CanCreate(input map[string]interface{}) (bool, error)
-	Create(input map[string]interface{}) error
+	Create(input map[string]interface{}) (int, error)
 	Update(id int, input map[string]interface{}) error
+	UpdateStatus(id int, status string) error
+	Refund(id int, quantity int) error
+
+	FindAllItems(interactionIds []int) ([]models.InteractionItem, error)
+	CreateItem(input map[string]interface{}) error
 }
 
 type Repository struct {
-	db *sqlx.DB
+	db database.Queryer
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
@@ -25,6 +35,12 @@ func NewRepository(db *sqlx.DB) *Repository {
 	}
 }
 
+func (s *Repository) WithTx(tx *sqlx.Tx) InteractionRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
 func (s *Repository) FindAll(filters map[string]interface{}) ([]models.InteractionBasic, error) {
 	interactions := []models.InteractionBasic{}
 	query := `
@@ -33,7 +49,14 @@ func (s *Repository) FindAll(filters map[string]interface{}) ([]models.Interacti
 			i.type AS type,
 			i.status AS status,
 			i.credit AS credit,
+			i.quantity AS quantity,
+			i.refunded_quantity AS refunded_quantity,
 			i.point AS point,
+			i.tier AS tier,
+			i.promotion_id AS promotion_id,
+			i.discount AS discount,
+			i.promotion_quantity AS promotion_quantity,
+			i.expires_at AS expires_at,
 			i.created_at AS created_at,
 			u.id AS "user.id",
 			u.name AS "user.name",
@@ -59,7 +82,10 @@ func (s *Repository) FindAll(filters map[string]interface{}) ([]models.Interacti
 		query += fmt.Sprintf(" AND u.id = %v", filters["child_id"])
 	}
 	if filters["stand_holder_id"] != nil {
-		query += fmt.Sprintf(" AND s.user_id = %v", filters["stand_holder_id"])
+		query += fmt.Sprintf(" AND s.id IN (SELECT stand_id FROM stand_members WHERE user_id = %v)", filters["stand_holder_id"])
+	}
+	if filters["status"] != nil {
+		query += fmt.Sprintf(" AND i.status = '%v'", filters["status"])
 	}
 	query += " ORDER BY i.created_at DESC"
 	err := s.db.Select(&interactions, query)
@@ -75,7 +101,55 @@ func (s *Repository) FindById(id int) (models.Interaction, error) {
 			i.type AS type,
 			i.status AS status,
 			i.credit AS credit,
+			i.quantity AS quantity,
+			i.refunded_quantity AS refunded_quantity,
+			i.point AS point,
+			i.tier AS tier,
+			i.promotion_id AS promotion_id,
+			i.discount AS discount,
+			i.promotion_quantity AS promotion_quantity,
+			i.expires_at AS expires_at,
+			i.created_at AS created_at,
+			u.id AS "user.id",
+			u.name AS "user.name",
+			u.email AS "user.email",
+			u.role AS "user.role",
+			s.id AS "stand.id",
+			s.name AS "stand.name",
+			s.description AS "stand.description",
+			s.type AS "stand.type",
+			s.price AS "stand.price",
+			k.id AS "kermesse.id",
+			k.name AS "kermesse.name",
+			k.description AS "kermesse.description",
+			k.status AS "kermesse.status"
+		FROM interactions i
+		JOIN users u ON i.user_id = u.id
+		JOIN stands s ON i.stand_id = s.id
+		JOIN kermesses k ON i.kermesse_id = k.id
+		WHERE i.id=$1
+	`
+	err := s.db.Get(&interaction, query, id)
+
+	return interaction, err
+}
+
+func (s *Repository) FindByIdForUpdate(id int) (models.Interaction, error) {
+	interaction := models.Interaction{}
+	query := `
+		SELECT
+			i.id AS id,
+			i.type AS type,
+			i.status AS status,
+			i.credit AS credit,
+			i.quantity AS quantity,
+			i.refunded_quantity AS refunded_quantity,
 			i.point AS point,
+			i.tier AS tier,
+			i.promotion_id AS promotion_id,
+			i.discount AS discount,
+			i.promotion_quantity AS promotion_quantity,
+			i.expires_at AS expires_at,
 			i.created_at AS created_at,
 			u.id AS "user.id",
 			u.name AS "user.name",
@@ -95,38 +169,94 @@ func (s *Repository) FindById(id int) (models.Interaction, error) {
 		JOIN stands s ON i.stand_id = s.id
 		JOIN kermesses k ON i.kermesse_id = k.id
 		WHERE i.id=$1
+		FOR UPDATE OF i
 	`
 	err := s.db.Get(&interaction, query, id)
 
 	return interaction, err
 }
 
+// FindAllExpiredIds returns the PENDING_APPROVAL interactions past their
+// deadline.
+func (s *Repository) FindAllExpiredIds() ([]int, error) {
+	ids := []int{}
+	query := "SELECT id FROM interactions WHERE status=$1 AND expires_at <= CURRENT_TIMESTAMP ORDER BY id"
+	err := s.db.Select(&ids, query, models.InteractionStatusPendingApproval)
+
+	return ids, err
+}
+
+// CanCreate tells if the user and the stand take part in the same running
+// kermesse, or in the kermesse given in input["kermesse_id"] whose state is
+// then checked by the caller.
 func (s *Repository) CanCreate(input map[string]interface{}) (bool, error) {
 	var isAssociated bool
-	query := `
+	condition := "k.status = $3"
+	args := []interface{}{input["user_id"], input["stand_id"], models.KermesseStatusRunning}
+	if input["kermesse_id"] != nil {
+		condition = "k.id = $3"
+		args[2] = input["kermesse_id"]
+	}
+	query := fmt.Sprintf(`
 		SELECT EXISTS (
 			SELECT 1
 			FROM kermesses_users ku
   		JOIN kermesses_stands ks ON ku.kermesse_id = ks.kermesse_id
 			JOIN kermesses k ON ku.kermesse_id = k.id
-  		WHERE ku.user_id = $1 AND ks.stand_id = $2 AND k.status = $3
+  		WHERE ku.user_id = $1 AND ks.stand_id = $2 AND %s
 		) AS is_associated
- 	`
-	err := s.db.QueryRow(query, input["user_id"], input["stand_id"], models.KermesseStatusStarted).Scan(&isAssociated)
+ 	`, condition)
+	err := s.db.QueryRow(query, args...).Scan(&isAssociated)
 
 	return isAssociated, err
 }
 
-func (s *Repository) Create(input map[string]interface{}) error {
-	query := "INSERT INTO interactions (user_id, kermesse_id, stand_id, type, credit) VALUES ($1, $2, $3, $4, $5)"
-	_, err := s.db.Exec(query, input["user_id"], input["kermesse_id"], input["stand_id"], input["type"], input["credit"])
+func (s *Repository) Create(input map[string]interface{}) (int, error) {
+	var id int
+	query := "INSERT INTO interactions (user_id, kermesse_id, stand_id, type, status, credit, quantity, expires_at, promotion_id, discount, promotion_quantity, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, COALESCE($12::TIMESTAMP WITH TIME ZONE, CURRENT_TIMESTAMP)) RETURNING id"
+	err := s.db.QueryRow(query, input["user_id"], input["kermesse_id"], input["stand_id"], input["type"], input["status"], input["credit"], input["quantity"], input["expires_at"], input["promotion_id"], input["discount"], input["promotion_quantity"], input["created_at"]).Scan(&id)
 
-	return err
+	return id, err
 }
 
 func (s *Repository) Update(id int, input map[string]interface{}) error {
-	query := "UPDATE interactions SET status=$1, point=$2 WHERE id=$3"
-	_, err := s.db.Exec(query, input["status"], input["point"], id)
+	query := "UPDATE interactions SET status=$1, point=$2, tier=$3, ended_at=CURRENT_TIMESTAMP WHERE id=$4"
+	_, err := s.db.Exec(query, input["status"], input["point"], input["tier"], id)
+
+	return err
+}
+
+func (s *Repository) UpdateStatus(id int, status string) error {
+	query := "UPDATE interactions SET status=$1 WHERE id=$2"
+	_, err := s.db.Exec(query, status, id)
+
+	return err
+}
+
+func (s *Repository) Refund(id int, quantity int) error {
+	query := `
+		UPDATE interactions
+		SET
+			refunded_quantity = refunded_quantity + $1,
+			status = CASE WHEN refunded_quantity + $1 >= quantity THEN $2::interactions_status_enum ELSE status END
+		WHERE id=$3
+	`
+	_, err := s.db.Exec(query, quantity, models.InteractionStatusRefunded, id)
+
+	return err
+}
+
+func (s *Repository) FindAllItems(interactionIds []int) ([]models.InteractionItem, error) {
+	items := []models.InteractionItem{}
+	query := "SELECT * FROM interaction_items WHERE interaction_id = ANY($1) ORDER BY interaction_id, id"
+	err := s.db.Select(&items, query, pq.Array(interactionIds))
+
+	return items, err
+}
+
+func (s *Repository) CreateItem(input map[string]interface{}) error {
+	query := "INSERT INTO interaction_items (interaction_id, product_id, name, price, quantity) VALUES ($1, $2, $3, $4, $5)"
+	_, err := s.db.Exec(query, input["interaction_id"], input["product_id"], input["name"], input["price"], input["quantity"])
 
 	return err
 }
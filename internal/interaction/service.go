@@ -4,35 +4,76 @@ import (
 	"context"
 	"database/sql"
 	goErrors "errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"time"
 
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/childrule"
+	"standmaster/internal/inventory"
 	"standmaster/internal/kermesse"
+	"standmaster/internal/ledger"
 	"standmaster/internal/models"
+	"standmaster/internal/product"
+	"standmaster/internal/promotion"
+	"standmaster/internal/scoring"
 	"standmaster/internal/stand"
+	"standmaster/internal/stock"
+	"standmaster/internal/tag"
 	"standmaster/internal/user"
 	"standmaster/pkg/errors"
 	"standmaster/pkg/utils"
+	"standmaster/third_party/database"
 )
 
 type InteractionService interface {
 	GetAll(ctx context.Context, params map[string]interface{}) ([]models.InteractionBasic, error)
 	Get(ctx context.Context, id int) (models.Interaction, error)
-	Create(ctx context.Context, input map[string]interface{}) error
+	Create(ctx context.Context, input map[string]interface{}) (models.Interaction, error)
+	CreateForUser(ctx context.Context, userId int, input map[string]interface{}) (models.Interaction, error)
+	CreateForUserWith(ctx context.Context, userId int, input map[string]interface{}, fn func(tx *sqlx.Tx, interactionId int) error) (models.Interaction, error)
 	Update(ctx context.Context, id int, input map[string]interface{}) error
+	Refund(ctx context.Context, id int, input map[string]interface{}) error
+
+	GetAllPending(ctx context.Context) ([]models.InteractionBasic, error)
+	Approve(ctx context.Context, id int) error
+	Reject(ctx context.Context, id int) error
+	ExpirePending() error
 }
 
 type Service struct {
-	repository         InteractionRepository
-	standRepository    stand.StandRepository
-	userRepository     user.UserRepository
-	kermesseRepository kermesse.KermesseRepository
+	repository          InteractionRepository
+	standRepository     stand.StandRepository
+	productRepository   product.ProductRepository
+	userRepository      user.UserRepository
+	kermesseRepository  kermesse.KermesseRepository
+	ledgerRepository    ledger.LedgerRepository
+	childRuleRepository childrule.ChildRuleRepository
+	scoringRepository   scoring.ScoringRepository
+	tagRepository       tag.TagRepository
+	promotionRepository promotion.PromotionRepository
+	stockRepository     stock.StockRepository
+	inventoryService    inventory.InventoryService
+	transactor          database.Transactor
 }
 
-func NewService(repository InteractionRepository, standRepository stand.StandRepository, userRepository user.UserRepository, kermesseRepository kermesse.KermesseRepository) *Service {
+func NewService(repository InteractionRepository, standRepository stand.StandRepository, productRepository product.ProductRepository, userRepository user.UserRepository, kermesseRepository kermesse.KermesseRepository, ledgerRepository ledger.LedgerRepository, childRuleRepository childrule.ChildRuleRepository, scoringRepository scoring.ScoringRepository, tagRepository tag.TagRepository, promotionRepository promotion.PromotionRepository, stockRepository stock.StockRepository, inventoryService inventory.InventoryService, transactor database.Transactor) *Service {
 	return &Service{
-		repository:         repository,
-		standRepository:    standRepository,
-		userRepository:     userRepository,
-		kermesseRepository: kermesseRepository,
+		repository:          repository,
+		standRepository:     standRepository,
+		productRepository:   productRepository,
+		userRepository:      userRepository,
+		kermesseRepository:  kermesseRepository,
+		ledgerRepository:    ledgerRepository,
+		childRuleRepository: childRuleRepository,
+		scoringRepository:   scoringRepository,
+		tagRepository:       tagRepository,
+		promotionRepository: promotionRepository,
+		stockRepository:     stockRepository,
+		inventoryService:    inventoryService,
+		transactor:          transactor,
 	}
 }
 
@@ -73,7 +114,7 @@ func (s *Service) GetAll(ctx context.Context, params map[string]interface{}) ([]
 		}
 	}
 
-	return interactions, nil
+	return s.withItems(interactions)
 }
 
 func (s *Service) Get(ctx context.Context, id int) (models.Interaction, error) {
@@ -91,143 +132,452 @@ func (s *Service) Get(ctx context.Context, id int) (models.Interaction, error) {
 		}
 	}
 
+	interaction.Items, err = s.repository.FindAllItems([]int{interaction.Id})
+	if err != nil {
+		return interaction, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
 	return interaction, nil
 }
 
-func (s *Service) Create(ctx context.Context, input map[string]interface{}) error {
+func (s *Service) Create(ctx context.Context, input map[string]interface{}) (models.Interaction, error) {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return models.Interaction{}, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	return s.CreateForUser(ctx, userId, input)
+}
+
+// CreateForUser charges the user for the stand, it is used when the purchase
+// is not made by the buyer, e.g. when a stand member calls the next child of
+// the queue. A sale recorded offline gives its time in input["sold_at"], the
+// opening of the stand is checked at that time and the pause is ignored.
+func (s *Service) CreateForUser(ctx context.Context, userId int, input map[string]interface{}) (models.Interaction, error) {
+	return s.CreateForUserWith(ctx, userId, input, nil)
+}
+
+// CreateForUserWith is CreateForUser running fn in the transaction of the
+// purchase once the interaction is created, the purchase is rolled back when
+// fn fails.
+func (s *Service) CreateForUserWith(ctx context.Context, userId int, input map[string]interface{}, fn func(tx *sqlx.Tx, interactionId int) error) (models.Interaction, error) {
 	standId, err := utils.GetIntFromMap(input, "stand_id")
 	if err != nil {
-		return errors.CustomError{
+		return models.Interaction{}, errors.CustomError{
 			Key: errors.BadRequest,
 			Err: err,
 		}
 	}
-	stand, err := s.standRepository.FindById(standId)
+	kermesseId, err := utils.GetIntFromMap(input, "kermesse_id")
 	if err != nil {
-		if goErrors.Is(err, sql.ErrNoRows) {
+		return models.Interaction{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+
+	cart, err := parseCart(input)
+	if err != nil {
+		return models.Interaction{}, err
+	}
+
+	soldAt, isOffline := input["sold_at"].(time.Time)
+	if !isOffline {
+		soldAt = time.Now()
+	}
+
+	var interactionId int
+	var alerts []models.StockAlert
+	err = s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+		standRepository := s.standRepository.WithTx(tx)
+		productRepository := s.productRepository.WithTx(tx)
+		userRepository := s.userRepository.WithTx(tx)
+		ledgerRepository := s.ledgerRepository.WithTx(tx)
+		childRuleRepository := s.childRuleRepository.WithTx(tx)
+		promotionRepository := s.promotionRepository.WithTx(tx)
+		stockRepository := s.stockRepository.WithTx(tx)
+
+		// lock the stand first so concurrent purchases can't oversell its stock
+		stand, err := standRepository.FindByIdForUpdate(standId)
+		if err != nil {
+			if goErrors.Is(err, sql.ErrNoRows) {
+				return errors.CustomError{
+					Key: errors.NotFound,
+					Err: err,
+				}
+			}
 			return errors.CustomError{
-				Key: errors.NotFound,
+				Key: errors.InternalServerError,
 				Err: err,
 			}
 		}
-		return errors.CustomError{
-			Key: errors.InternalServerError,
-			Err: err,
-		}
-	}
 
-	userId, ok := ctx.Value(models.UserIDKey).(int)
-	if !ok {
-		return errors.CustomError{
-			Key: errors.Unauthorized,
-			Err: goErrors.New("user id not found in context"),
+		// purchases are only taken while the kermesse and the stand are open
+		if err := kermesse.CheckOpen(s.kermesseRepository, kermesseId, soldAt); err != nil {
+			return err
 		}
-	}
-	user, err := s.userRepository.FindById(userId)
-	if err != nil {
-		if goErrors.Is(err, sql.ErrNoRows) {
+		opening, err := standRepository.FindOpening(stand.Id, kermesseId, soldAt)
+		if err != nil {
 			return errors.CustomError{
-				Key: errors.NotFound,
+				Key: errors.InternalServerError,
 				Err: err,
 			}
 		}
-		return errors.CustomError{
-			Key: errors.InternalServerError,
-			Err: err,
+		if !opening.IsScheduled || (stand.IsPaused && !isOffline) {
+			return errors.CustomError{
+				Key: errors.StandClosed,
+				Err: goErrors.New("stand is closed"),
+			}
 		}
-	}
 
-	canCreate, err := s.repository.CanCreate(map[string]interface{}{
-		"user_id":  userId,
-		"stand_id": standId,
-	})
-	if err != nil {
-		return errors.CustomError{
-			Key: errors.InternalServerError,
-			Err: err,
+		// then the products of the cart, in id order, before the user
+		var items []models.InteractionItem
+		if cart != nil && stand.Type != models.InteractionTypeConsumption {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("stand doesn't sell products"),
+			}
 		}
-	}
-	if !canCreate {
-		return errors.CustomError{
-			Key: errors.Forbidden,
-			Err: goErrors.New("forbidden"),
+		for _, line := range cart {
+			product, err := productRepository.FindByIdForUpdate(line.ProductId)
+			if err != nil {
+				if goErrors.Is(err, sql.ErrNoRows) {
+					return errors.CustomError{
+						Key: errors.NotFound,
+						Err: err,
+					}
+				}
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+			if product.StandId != stand.Id || !product.IsActive {
+				return errors.CustomError{
+					Key: errors.BadRequest,
+					Err: goErrors.New("product is not sold by the stand"),
+				}
+			}
+			if product.Stock < line.Quantity {
+				return errors.CustomError{
+					Key: errors.NotEnoughStock,
+					Err: goErrors.New("not enough stock"),
+				}
+			}
+
+			items = append(items, models.InteractionItem{
+				ProductId: product.Id,
+				Name:      product.Name,
+				Price:     product.Price,
+				Quantity:  line.Quantity,
+			})
 		}
-	}
 
-	// calculate total price
-	quantity := 1
-	totalPrice := stand.Price
-	if stand.Type == models.InteractionTypeConsumption {
-		quantity, err = utils.GetIntFromMap(input, "quantity")
+		user, err := userRepository.FindByIdForUpdate(userId)
 		if err != nil {
+			if goErrors.Is(err, sql.ErrNoRows) {
+				return errors.CustomError{
+					Key: errors.NotFound,
+					Err: err,
+				}
+			}
 			return errors.CustomError{
-				Key: errors.BadRequest,
+				Key: errors.InternalServerError,
 				Err: err,
 			}
 		}
-		totalPrice = stand.Price * quantity
-	}
 
-	// check stand's stock and user credit
-	if stand.Type == models.InteractionTypeConsumption {
-		if stand.Stock < quantity {
+		canCreate, err := repository.CanCreate(map[string]interface{}{
+			"user_id":     userId,
+			"stand_id":    standId,
+			"kermesse_id": kermesseId,
+		})
+		if err != nil {
 			return errors.CustomError{
-				Key: errors.BadRequest,
-				Err: goErrors.New("not enough stock"),
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		if !canCreate {
+			return errors.CustomError{
+				Key: errors.Forbidden,
+				Err: goErrors.New("forbidden"),
 			}
 		}
-	}
 
-	// check user's credit
-	if user.Credit < totalPrice {
-		return errors.CustomError{
-			Key: errors.BadRequest,
-			Err: goErrors.New("not enough credit"),
+		// calculate total price
+		quantity := 1
+		totalPrice := stand.Price
+		if items != nil {
+			quantity, totalPrice = 0, 0
+			for _, item := range items {
+				quantity += item.Quantity
+				totalPrice += item.Price * item.Quantity
+			}
+		} else if stand.Type == models.InteractionTypeConsumption {
+			quantity, err = utils.GetIntFromMap(input, "quantity")
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.BadRequest,
+					Err: err,
+				}
+			}
+			if quantity <= 0 {
+				return errors.CustomError{
+					Key: errors.BadRequest,
+					Err: goErrors.New("quantity must be positive"),
+				}
+			}
+			totalPrice = stand.Price * quantity
+		}
+
+		// apply the best promotion of the stand
+		lines := []promotion.Line{{Price: stand.Price, Quantity: quantity}}
+		if items != nil {
+			lines = nil
+			for _, item := range items {
+				lines = append(lines, promotion.Line{ProductId: &item.ProductId, Price: item.Price, Quantity: item.Quantity})
+			}
+		}
+		quote, err := promotion.Price(promotionRepository, stand.Id, lines, soldAt)
+		if err != nil {
+			return err
+		}
+		totalPrice = quote.Total
+
+		// check the rules the parent set for the child
+		err = childrule.Check(childRuleRepository, userId, childrule.Purchase{
+			KermesseId: kermesseId,
+			StandId:    stand.Id,
+			StandType:  stand.Type,
+			Amount:     totalPrice,
+			At:         soldAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		// purchases above the threshold set by the parent wait for their approval
+		needsApproval, err := childrule.NeedsApproval(childRuleRepository, userId, totalPrice)
+		if err != nil {
+			return err
+		}
+
+		// check stand's stock, the stock of the products was checked above
+		if items == nil && stand.Type == models.InteractionTypeConsumption {
+			if stand.Stock < quantity {
+				return errors.CustomError{
+					Key: errors.NotEnoughStock,
+					Err: goErrors.New("not enough stock"),
+				}
+			}
+		}
+
+		// check user's credit
+		if user.Credit < totalPrice {
+			return errors.CustomError{
+				Key: errors.NotEnoughCredit,
+				Err: goErrors.New("not enough credit"),
+			}
 		}
-	}
 
-	// decrease stand's stock
-	if stand.Type == models.InteractionTypeConsumption {
-		err = s.standRepository.UpdateStock(standId, -quantity)
+		input["user_id"] = user.Id
+		input["type"] = stand.Type
+		input["status"] = models.InteractionStatusStarted
+		input["credit"] = totalPrice
+		input["quantity"] = quantity
+		input["created_at"] = nil
+		if isOffline {
+			input["created_at"] = soldAt
+		}
+		input["discount"] = quote.Discount
+		input["promotion_id"] = nil
+		input["promotion_quantity"] = quote.Quantity
+		if quote.Promotion != nil {
+			input["promotion_id"] = quote.Promotion.Id
+		}
+		if needsApproval {
+			ttl, err := approvalTTL()
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+			input["status"] = models.InteractionStatusPendingApproval
+			input["expires_at"] = time.Now().Add(ttl)
+		}
+
+		interactionId, err = repository.Create(input)
 		if err != nil {
 			return errors.CustomError{
 				Key: errors.InternalServerError,
 				Err: err,
 			}
 		}
+
+		for _, item := range items {
+			err = repository.CreateItem(map[string]interface{}{
+				"interaction_id": interactionId,
+				"product_id":     item.ProductId,
+				"name":           item.Name,
+				"price":          item.Price,
+				"quantity":       item.Quantity,
+			})
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+		}
+
+		if fn != nil {
+			if err := fn(tx, interactionId); err != nil {
+				return err
+			}
+		}
+
+		// decrease products' or stand's stock, the seller is the author of
+		// the movement
+		authorId, ok := ctx.Value(models.UserIDKey).(int)
+		if !ok {
+			authorId = userId
+		}
+		movement := map[string]interface{}{
+			"stand_id":       stand.Id,
+			"kermesse_id":    kermesseId,
+			"kind":           models.StockMovementKindSale,
+			"reason":         "sale",
+			"user_id":        authorId,
+			"interaction_id": interactionId,
+		}
+		for _, item := range items {
+			movement["product_id"] = item.ProductId
+			movement["quantity"] = -item.Quantity
+			alert, err := stockRepository.Create(movement)
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+			if alert != nil {
+				alerts = append(alerts, *alert)
+			}
+		}
+		if items == nil && stand.Type == models.InteractionTypeConsumption {
+			movement["quantity"] = -quantity
+			alert, err := stockRepository.Create(movement)
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+			if alert != nil {
+				alerts = append(alerts, *alert)
+			}
+		}
+
+		// hold user's credit until the parent decides
+		if needsApproval {
+			err = ledgerRepository.Create(map[string]interface{}{
+				"debit_user_id":  userId,
+				"amount":         totalPrice,
+				"kind":           models.CreditTransactionKindHold,
+				"interaction_id": interactionId,
+			})
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+
+			return nil
+		}
+
+		// move user's credit to the stand holder
+		if totalPrice > 0 {
+			err = ledgerRepository.Create(map[string]interface{}{
+				"debit_user_id":  userId,
+				"credit_user_id": stand.UserId,
+				"amount":         totalPrice,
+				"kind":           models.CreditTransactionKindInteraction,
+				"interaction_id": interactionId,
+			})
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.Interaction{}, errors.FromError(err)
+	}
+
+	// the purchase is done even if the alerts can't be sent
+	if err := s.inventoryService.Alert(alerts); err != nil {
+		log.Printf("Error sending stock alerts of interaction %d: %v\n", interactionId, err)
 	}
 
-	// decrease user's credit
-	err = s.userRepository.UpdateCredit(userId, -totalPrice)
+	interaction, err := s.Get(ctx, interactionId)
 	if err != nil {
-		return errors.CustomError{
-			Key: errors.InternalServerError,
-			Err: err,
-		}
+		return interaction, err
 	}
 
-	// increase stand holder's credit
-	err = s.userRepository.UpdateCredit(stand.UserId, totalPrice)
+	interaction.Warnings, err = s.allergenWarnings(userId, standId)
 	if err != nil {
-		return errors.CustomError{
+		return interaction, err
+	}
+
+	return interaction, nil
+}
+
+// allergenWarnings lists the allergens declared by the stand that the user is
+// allergic to.
+func (s *Service) allergenWarnings(userId int, standId int) ([]string, error) {
+	allergies, err := s.tagRepository.FindAllergies(userId)
+	if err != nil {
+		return nil, errors.CustomError{
 			Key: errors.InternalServerError,
 			Err: err,
 		}
 	}
+	if len(allergies) == 0 {
+		return nil, nil
+	}
 
-	input["user_id"] = user.Id
-	input["type"] = stand.Type
-	input["credit"] = totalPrice
-
-	err = s.repository.Create(input)
+	standTags, err := s.tagRepository.FindAllByStandIds([]int{standId})
 	if err != nil {
-		return errors.CustomError{
+		return nil, errors.CustomError{
 			Key: errors.InternalServerError,
 			Err: err,
 		}
 	}
 
-	return nil
+	var warnings []string
+	for _, standTag := range standTags {
+		for _, allergy := range allergies {
+			if standTag.Id == allergy.Id {
+				warnings = append(warnings, fmt.Sprintf("stand declares %s, an allergy of the user", allergy.Name))
+			}
+		}
+	}
+
+	return warnings, nil
 }
 
 func (s *Service) Update(ctx context.Context, id int, input map[string]interface{}) error {
@@ -251,6 +601,13 @@ func (s *Service) Update(ctx context.Context, id int, input map[string]interface
 			Err: goErrors.New("interaction type is not activity"),
 		}
 	}
+	// an activity is ended, and its points awarded, only once
+	if interaction.Status != models.InteractionStatusStarted {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("interaction is not started"),
+		}
+	}
 
 	kermesse, err := s.kermesseRepository.FindById(interaction.Kermesse.Id)
 	if err != nil {
@@ -265,10 +622,10 @@ func (s *Service) Update(ctx context.Context, id int, input map[string]interface
 			Err: err,
 		}
 	}
-	if kermesse.Status == models.KermesseStatusEnded {
+	if kermesse.Status != models.KermesseStatusRunning {
 		return errors.CustomError{
 			Key: errors.BadRequest,
-			Err: goErrors.New("kermesse is ended"),
+			Err: goErrors.New("kermesse is not running"),
 		}
 	}
 
@@ -293,16 +650,32 @@ func (s *Service) Update(ctx context.Context, id int, input map[string]interface
 			Err: goErrors.New("user id not found in context"),
 		}
 	}
-	if stand.UserId != userId {
+
+	// any member of the stand can end an activity, owners and cashiers alike
+	member, err := s.standRepository.FindMemberByUserId(userId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if member.StandId != stand.Id {
 		return errors.CustomError{
 			Key: errors.Forbidden,
 			Err: goErrors.New("forbidden"),
 		}
 	}
 
+	// the points must follow the scoring rule of the stand in the kermesse
+	point, tier, err := scoring.Award(s.scoringRepository, interaction.Kermesse.Id, stand.Id, input)
+	if err != nil {
+		return err
+	}
+
 	err = s.repository.Update(id, map[string]interface{}{
 		"status": models.InteractionStatusEnded,
-		"point":  input["point"],
+		"point":  point,
+		"tier":   tier,
 	})
 	if err != nil {
 		return errors.CustomError{
@@ -313,3 +686,613 @@ func (s *Service) Update(ctx context.Context, id int, input map[string]interface
 
 	return nil
 }
+
+func (s *Service) Refund(ctx context.Context, id int, input map[string]interface{}) error {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+		standRepository := s.standRepository.WithTx(tx)
+		userRepository := s.userRepository.WithTx(tx)
+		ledgerRepository := s.ledgerRepository.WithTx(tx)
+
+		interaction, err := repository.FindByIdForUpdate(id)
+		if err != nil {
+			if goErrors.Is(err, sql.ErrNoRows) {
+				return errors.CustomError{
+					Key: errors.NotFound,
+					Err: err,
+				}
+			}
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		if !isSettled(interaction.Status) {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("interaction is not approved"),
+			}
+		}
+
+		if interaction.Kermesse.Status != models.KermesseStatusRunning {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("kermesse is not running"),
+			}
+		}
+
+		kermesse, err := s.kermesseRepository.FindById(interaction.Kermesse.Id)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		// same locking order as a purchase: stand first, then the users
+		stand, err := standRepository.FindByIdForUpdate(interaction.Stand.Id)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		member, err := standRepository.FindMemberByUserId(userId)
+		if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		isOwner := member.StandId == stand.Id && member.Role == models.StandMemberRoleOwner
+		if !isOwner && kermesse.UserId != userId {
+			return errors.CustomError{
+				Key: errors.Forbidden,
+				Err: goErrors.New("forbidden"),
+			}
+		}
+
+		remaining := interaction.Quantity - interaction.RefundedQuantity
+		if remaining <= 0 {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("interaction is already refunded"),
+			}
+		}
+
+		// refund the remaining quantity unless told otherwise
+		quantity := remaining
+		if input["quantity"] != nil {
+			quantity, err = utils.GetIntFromMap(input, "quantity")
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.BadRequest,
+					Err: err,
+				}
+			}
+		}
+		if quantity <= 0 || quantity > remaining {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("invalid quantity"),
+			}
+		}
+
+		// the quantity of a cart doesn't tell which products to put back
+		items, err := repository.FindAllItems([]int{interaction.Id})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		if len(items) > 0 && quantity != remaining {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("cart interactions are refunded in full"),
+			}
+		}
+
+		// computed on the cumulated quantity so partial refunds add up to the credit
+		amount := interaction.Credit*(interaction.RefundedQuantity+quantity)/interaction.Quantity -
+			interaction.Credit*interaction.RefundedQuantity/interaction.Quantity
+
+		holder, err := userRepository.FindByIdForUpdate(stand.UserId)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		if holder.Credit < amount {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("not enough credit"),
+			}
+		}
+
+		if amount > 0 {
+			err = ledgerRepository.Create(map[string]interface{}{
+				"debit_user_id":  stand.UserId,
+				"credit_user_id": interaction.User.Id,
+				"amount":         amount,
+				"kind":           models.CreditTransactionKindRefund,
+				"interaction_id": interaction.Id,
+			})
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+		}
+
+		// put the items back in stock
+		err = s.restock(tx, interaction, items, quantity, &userId, "refund")
+		if err != nil {
+			return err
+		}
+
+		err = s.releasePromotion(tx, interaction, quantity)
+		if err != nil {
+			return err
+		}
+
+		err = repository.Refund(id, quantity)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+func (s *Service) GetAllPending(ctx context.Context) ([]models.InteractionBasic, error) {
+	parentId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return nil, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	interactions, err := s.repository.FindAll(map[string]interface{}{
+		"parent_id": parentId,
+		"status":    models.InteractionStatusPendingApproval,
+	})
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return s.withItems(interactions)
+}
+
+// Approve releases the credit held for a child's purchase and moves it to the
+// stand holder, the interaction then goes on as any other.
+func (s *Service) Approve(ctx context.Context, id int) error {
+	parentId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+		ledgerRepository := s.ledgerRepository.WithTx(tx)
+
+		interaction, err := s.findPending(repository, id, parentId)
+		if err != nil {
+			return err
+		}
+
+		if interaction.Kermesse.Status != models.KermesseStatusRunning {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("kermesse is not running"),
+			}
+		}
+
+		stand, err := s.standRepository.WithTx(tx).FindById(interaction.Stand.Id)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		err = ledgerRepository.Create(map[string]interface{}{
+			"credit_user_id": interaction.User.Id,
+			"amount":         interaction.Credit,
+			"kind":           models.CreditTransactionKindHoldRelease,
+			"interaction_id": interaction.Id,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		err = ledgerRepository.Create(map[string]interface{}{
+			"debit_user_id":  interaction.User.Id,
+			"credit_user_id": stand.UserId,
+			"amount":         interaction.Credit,
+			"kind":           models.CreditTransactionKindInteraction,
+			"interaction_id": interaction.Id,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		err = repository.UpdateStatus(interaction.Id, models.InteractionStatusStarted)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+func (s *Service) Reject(ctx context.Context, id int) error {
+	parentId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		interaction, err := s.findPending(s.repository.WithTx(tx), id, parentId)
+		if err != nil {
+			return err
+		}
+
+		return s.release(tx, interaction, models.InteractionStatusRejected, &parentId)
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+// ExpirePending releases the holds of the interactions the parent didn't
+// approve in time, it's run by the scheduler. A hold that can't be released
+// doesn't hold back the others.
+func (s *Service) ExpirePending() error {
+	ids, err := s.repository.FindAllExpiredIds()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+			interaction, err := s.repository.WithTx(tx).FindByIdForUpdate(id)
+			if err != nil {
+				return err
+			}
+			// approved or rejected since it was listed
+			if interaction.Status != models.InteractionStatusPendingApproval {
+				return nil
+			}
+
+			return s.release(tx, interaction, models.InteractionStatusExpired, nil)
+		})
+		if err != nil {
+			log.Printf("Error expiring interaction %d: %v\n", id, err)
+		}
+	}
+
+	return nil
+}
+
+// findPending locks the interaction awaiting the approval of parentId.
+func (s *Service) findPending(repository InteractionRepository, id int, parentId int) (models.Interaction, error) {
+	interaction, err := repository.FindByIdForUpdate(id)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return models.Interaction{}, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return models.Interaction{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	child, err := s.userRepository.FindById(interaction.User.Id)
+	if err != nil {
+		return models.Interaction{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if child.ParentId == nil || *child.ParentId != parentId {
+		return models.Interaction{}, errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("forbidden"),
+		}
+	}
+
+	if interaction.Status != models.InteractionStatusPendingApproval {
+		return models.Interaction{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("interaction is not pending approval"),
+		}
+	}
+	if interaction.ExpiresAt != nil && interaction.ExpiresAt.Before(time.Now()) {
+		return models.Interaction{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("interaction approval expired"),
+		}
+	}
+
+	return interaction, nil
+}
+
+// release gives the held credit back to the child and the held items back to
+// the stand, authorId is nil when the hold expired.
+func (s *Service) release(tx *sqlx.Tx, interaction models.Interaction, status string, authorId *int) error {
+	items, err := s.repository.WithTx(tx).FindAllItems([]int{interaction.Id})
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	// same locking order as a purchase: stock first, then the child
+	reason := "purchase rejected"
+	if status == models.InteractionStatusExpired {
+		reason = "purchase expired"
+	}
+	err = s.restock(tx, interaction, items, interaction.Quantity, authorId, reason)
+	if err != nil {
+		return err
+	}
+
+	err = s.releasePromotion(tx, interaction, interaction.Quantity)
+	if err != nil {
+		return err
+	}
+
+	err = s.ledgerRepository.WithTx(tx).Create(map[string]interface{}{
+		"credit_user_id": interaction.User.Id,
+		"amount":         interaction.Credit,
+		"kind":           models.CreditTransactionKindHoldRelease,
+		"interaction_id": interaction.Id,
+	})
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	err = s.repository.WithTx(tx).UpdateStatus(interaction.Id, status)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// restock puts quantity items of the interaction back in stock, in the
+// products of its cart when it has one, and logs them as refunded.
+func (s *Service) restock(tx *sqlx.Tx, interaction models.Interaction, items []models.InteractionItem, quantity int, authorId *int, reason string) error {
+	if interaction.Type != models.InteractionTypeConsumption {
+		return nil
+	}
+
+	stockRepository := s.stockRepository.WithTx(tx)
+	movement := map[string]interface{}{
+		"stand_id":       interaction.Stand.Id,
+		"kermesse_id":    interaction.Kermesse.Id,
+		"kind":           models.StockMovementKindRefund,
+		"quantity":       quantity,
+		"reason":         reason,
+		"user_id":        authorId,
+		"interaction_id": interaction.Id,
+	}
+
+	if len(items) == 0 {
+		if _, err := stockRepository.Create(movement); err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	}
+
+	for _, item := range items {
+		movement["product_id"] = item.ProductId
+		movement["quantity"] = item.Quantity
+		if _, err := stockRepository.Create(movement); err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// releasePromotion gives back to the promotion of the interaction the units it
+// discounted on the quantity undone, computed on the cumulated quantity so
+// partial refunds add up to what was used.
+func (s *Service) releasePromotion(tx *sqlx.Tx, interaction models.Interaction, quantity int) error {
+	if interaction.PromotionId == nil || interaction.PromotionQuantity == 0 {
+		return nil
+	}
+
+	units := interaction.PromotionQuantity*(interaction.RefundedQuantity+quantity)/interaction.Quantity -
+		interaction.PromotionQuantity*interaction.RefundedQuantity/interaction.Quantity
+	if units <= 0 {
+		return nil
+	}
+
+	if err := s.promotionRepository.WithTx(tx).Release(*interaction.PromotionId, units); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// withItems sets the cart of each interaction.
+func (s *Service) withItems(interactions []models.InteractionBasic) ([]models.InteractionBasic, error) {
+	ids := make([]int, len(interactions))
+	for i, interaction := range interactions {
+		ids[i] = interaction.Id
+	}
+
+	items, err := s.repository.FindAllItems(ids)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	itemsByInteraction := map[int][]models.InteractionItem{}
+	for _, item := range items {
+		itemsByInteraction[item.InteractionId] = append(itemsByInteraction[item.InteractionId], item)
+	}
+	for i := range interactions {
+		interactions[i].Items = itemsByInteraction[interactions[i].Id]
+	}
+
+	return interactions, nil
+}
+
+// cartLine is a product of the cart of a purchase.
+type cartLine struct {
+	ProductId int
+	Quantity  int
+}
+
+// parseCart reads the items of input, merged by product and sorted by id so
+// products are always locked in the same order. It returns nil when the
+// purchase has no cart.
+func parseCart(input map[string]interface{}) ([]cartLine, error) {
+	if input["items"] == nil {
+		return nil, nil
+	}
+
+	values, ok := input["items"].([]interface{})
+	if !ok || len(values) == 0 {
+		return nil, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("items is not a valid cart"),
+		}
+	}
+
+	quantities := map[int]int{}
+	for _, value := range values {
+		line, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("items is not a valid cart"),
+			}
+		}
+		productId, err := utils.GetIntFromMap(line, "product_id")
+		if err != nil {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		quantity, err := utils.GetIntFromMap(line, "quantity")
+		if err != nil {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		if quantity <= 0 {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("quantity must be positive"),
+			}
+		}
+		quantities[productId] += quantity
+	}
+
+	cart := []cartLine{}
+	for productId, quantity := range quantities {
+		cart = append(cart, cartLine{
+			ProductId: productId,
+			Quantity:  quantity,
+		})
+	}
+	slices.SortFunc(cart, func(a, b cartLine) int {
+		return a.ProductId - b.ProductId
+	})
+
+	return cart, nil
+}
+
+// isSettled tells if the purchase of the interaction went through and still
+// stands: it isn't awaiting approval, rejected, expired or refunded.
+func isSettled(status string) bool {
+	return status != models.InteractionStatusPendingApproval &&
+		status != models.InteractionStatusRejected &&
+		status != models.InteractionStatusExpired &&
+		status != models.InteractionStatusRefunded
+}
+
+// approvalTTL is how long a parent has to approve a purchase, set by
+// INTERACTION_APPROVAL_TTL (30m by default).
+func approvalTTL() (time.Duration, error) {
+	value := os.Getenv("INTERACTION_APPROVAL_TTL")
+	if value == "" {
+		return 30 * time.Minute, nil
+	}
+
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,489 @@
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/kermesse"
+	"standmaster/internal/models"
+	"standmaster/internal/notification"
+	"standmaster/internal/product"
+	"standmaster/internal/stand"
+	"standmaster/internal/stock"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/utils"
+	"standmaster/third_party/database"
+)
+
+type InventoryService interface {
+	GetMovements(ctx context.Context, standId int, params map[string]interface{}) ([]models.StockMovement, error)
+	Restock(ctx context.Context, standId int, input map[string]interface{}) error
+	Adjust(ctx context.Context, standId int, input map[string]interface{}) error
+	GetReport(ctx context.Context, standId int, kermesseId int) ([]models.StockReportLine, error)
+	SetThreshold(ctx context.Context, standId int, input map[string]interface{}) error
+	Alert(alerts []models.StockAlert) error
+}
+
+type Service struct {
+	repository         stock.StockRepository
+	standRepository    stand.StandRepository
+	productRepository  product.ProductRepository
+	kermesseRepository kermesse.KermesseRepository
+	notifier           notification.Notifier
+	transactor         database.Transactor
+}
+
+func NewService(repository stock.StockRepository, standRepository stand.StandRepository, productRepository product.ProductRepository, kermesseRepository kermesse.KermesseRepository, notifier notification.Notifier, transactor database.Transactor) *Service {
+	return &Service{
+		repository:         repository,
+		standRepository:    standRepository,
+		productRepository:  productRepository,
+		kermesseRepository: kermesseRepository,
+		notifier:           notifier,
+		transactor:         transactor,
+	}
+}
+
+// GetMovements lists the stock movements of the stand to its members, the
+// latest first.
+func (s *Service) GetMovements(ctx context.Context, standId int, params map[string]interface{}) ([]models.StockMovement, error) {
+	if _, err := s.checkMember(ctx, standId); err != nil {
+		return nil, err
+	}
+
+	filters := map[string]interface{}{}
+	for _, key := range []string{"kermesse_id", "product_id"} {
+		if params[key] == nil {
+			continue
+		}
+		value, _ := params[key].(string)
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		filters[key] = id
+	}
+
+	movements, err := s.repository.FindAllByStandId(standId, filters)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return movements, nil
+}
+
+// Restock lets an owner of the stand add the quantity delivered to its stock,
+// or to the stock of one of its products when product_id is given.
+func (s *Service) Restock(ctx context.Context, standId int, input map[string]interface{}) error {
+	member, err := s.checkMember(ctx, standId)
+	if err != nil {
+		return err
+	}
+	if member.Role != models.StandMemberRoleOwner {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not an owner of the stand"),
+		}
+	}
+
+	quantity, err := utils.GetIntFromMap(input, "quantity")
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+	if quantity <= 0 {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("quantity must be positive"),
+		}
+	}
+
+	reason, _ := input["reason"].(string)
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reason = "restock"
+	}
+
+	return s.move(member.UserId, standId, input, models.StockMovementKindRestock, quantity, reason)
+}
+
+// Adjust lets an owner of the stand correct its stock, or the stock of one of
+// its products, after a count or a loss. An ADJUSTMENT is signed while a WASTE is the number of
+// units thrown away, both need a reason.
+func (s *Service) Adjust(ctx context.Context, standId int, input map[string]interface{}) error {
+	member, err := s.checkMember(ctx, standId)
+	if err != nil {
+		return err
+	}
+	if member.Role != models.StandMemberRoleOwner {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not an owner of the stand"),
+		}
+	}
+
+	kind, _ := input["kind"].(string)
+	if kind != models.StockMovementKindAdjustment && kind != models.StockMovementKindWaste {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("kind must be ADJUSTMENT or WASTE"),
+		}
+	}
+
+	quantity, err := utils.GetIntFromMap(input, "quantity")
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+	if kind == models.StockMovementKindWaste {
+		if quantity <= 0 {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("quantity must be positive"),
+			}
+		}
+		quantity = -quantity
+	}
+	if quantity == 0 {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("quantity can't be zero"),
+		}
+	}
+
+	reason, _ := input["reason"].(string)
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("reason is missing"),
+		}
+	}
+
+	return s.move(member.UserId, standId, input, kind, quantity, reason)
+}
+
+// GetReport sums the stock movements of the stand during the kermesse.
+func (s *Service) GetReport(ctx context.Context, standId int, kermesseId int) ([]models.StockReportLine, error) {
+	if _, err := s.checkMember(ctx, standId); err != nil {
+		return nil, err
+	}
+	if err := s.checkKermesse(standId, kermesseId); err != nil {
+		return nil, err
+	}
+
+	lines, err := s.repository.Report(standId, kermesseId)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return lines, nil
+}
+
+// SetThreshold lets an owner of the stand set the low-stock threshold of the
+// stand, or of one of its products when product_id is given. A null
+// threshold stops the low-stock alerts, stock-outs are always alerted.
+func (s *Service) SetThreshold(ctx context.Context, standId int, input map[string]interface{}) error {
+	member, err := s.checkMember(ctx, standId)
+	if err != nil {
+		return err
+	}
+	if member.Role != models.StandMemberRoleOwner {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not an owner of the stand"),
+		}
+	}
+
+	var threshold *int
+	if input["threshold"] != nil {
+		value, err := utils.GetIntFromMap(input, "threshold")
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		if value < 0 {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("threshold can't be negative"),
+			}
+		}
+		threshold = &value
+	}
+
+	productId, err := parseProductId(input)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.SetThreshold(standId, productId, threshold); err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: goErrors.New("product not found"),
+			}
+		}
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// Alert notifies the members of the stand and the organizer of the kermesse
+// of the alerts raised by stock movements. It is called once the movements
+// are saved, a notification failing to be sent doesn't undo them.
+func (s *Service) Alert(alerts []models.StockAlert) error {
+	for _, alert := range alerts {
+		stand, err := s.standRepository.FindById(alert.StandId)
+		if err != nil {
+			return err
+		}
+
+		members, err := s.standRepository.FindAllMembers(alert.StandId)
+		if err != nil {
+			return err
+		}
+		userIds := []int{}
+		for _, member := range members {
+			userIds = append(userIds, member.UserId)
+		}
+		if alert.KermesseId != nil {
+			kermesse, err := s.kermesseRepository.FindById(*alert.KermesseId)
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(userIds, kermesse.UserId) {
+				userIds = append(userIds, kermesse.UserId)
+			}
+		}
+
+		name := stand.Name
+		if alert.ProductId != nil {
+			product, err := s.productRepository.FindById(*alert.ProductId)
+			if err != nil {
+				return err
+			}
+			name = product.Name
+		}
+
+		notification := models.Notification{
+			Kind:       alert.Kind,
+			Title:      fmt.Sprintf("Stock bas : %s", name),
+			Message:    fmt.Sprintf("Il ne reste que %d %s au stand %s.", alert.Stock, name, stand.Name),
+			StandId:    &alert.StandId,
+			KermesseId: alert.KermesseId,
+		}
+		if alert.Kind == models.StockAlertOut {
+			notification.Title = fmt.Sprintf("Rupture de stock : %s", name)
+			notification.Message = fmt.Sprintf("Il n'y a plus de %s au stand %s.", name, stand.Name)
+		}
+
+		if err := s.notifier.Notify(userIds, notification); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// move logs a movement of quantity units, the stock can't go below zero. The
+// movement belongs to the kermesse given in input, or to the one the stand is
+// taking part in.
+func (s *Service) move(userId int, standId int, input map[string]interface{}, kind string, quantity int, reason string) error {
+	var kermesseId *int
+	if input["kermesse_id"] != nil {
+		id, err := utils.GetIntFromMap(input, "kermesse_id")
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		if err := s.checkKermesse(standId, id); err != nil {
+			return err
+		}
+		kermesseId = &id
+	}
+
+	productId, err := parseProductId(input)
+	if err != nil {
+		return err
+	}
+
+	var alert *models.StockAlert
+	err = s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		// same locking order as a purchase: stand first, then the product
+		stand, err := s.standRepository.WithTx(tx).FindByIdForUpdate(standId)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		current := stand.Stock
+		if productId != nil {
+			product, err := s.productRepository.WithTx(tx).FindByIdForUpdate(*productId)
+			if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+			if product.StandId != standId {
+				return errors.CustomError{
+					Key: errors.NotFound,
+					Err: goErrors.New("product not found"),
+				}
+			}
+			current = product.Stock
+		} else if stand.Type != models.InteractionTypeConsumption {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("stand doesn't sell consumptions"),
+			}
+		}
+
+		if current+quantity < 0 {
+			return errors.CustomError{
+				Key: errors.NotEnoughStock,
+				Err: goErrors.New("not enough stock"),
+			}
+		}
+
+		movement := map[string]interface{}{
+			"stand_id":    standId,
+			"kermesse_id": kermesseId,
+			"kind":        kind,
+			"quantity":    quantity,
+			"reason":      reason,
+			"user_id":     userId,
+		}
+		if productId != nil {
+			movement["product_id"] = *productId
+		}
+		alert, err = s.repository.WithTx(tx).Create(movement)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	// the movement is saved even if the alert can't be sent
+	if alert != nil {
+		if err := s.Alert([]models.StockAlert{*alert}); err != nil {
+			log.Printf("Error sending stock alert of stand %d: %v\n", standId, err)
+		}
+	}
+
+	return nil
+}
+
+// parseProductId reads the product_id given in input, nil when there is none.
+func parseProductId(input map[string]interface{}) (*int, error) {
+	if input["product_id"] == nil {
+		return nil, nil
+	}
+
+	productId, err := utils.GetIntFromMap(input, "product_id")
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+
+	return &productId, nil
+}
+
+func (s *Service) checkKermesse(standId int, kermesseId int) error {
+	hasStand, err := s.kermesseRepository.HasStand(kermesseId, standId)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if !hasStand {
+		return errors.CustomError{
+			Key: errors.NotFound,
+			Err: goErrors.New("stand is not in the kermesse"),
+		}
+	}
+
+	return nil
+}
+
+// checkMember makes sure the user is a member of the stand and returns their
+// membership.
+func (s *Service) checkMember(ctx context.Context, standId int) (models.StandMember, error) {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return models.StandMember{}, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	stand, err := s.standRepository.FindById(standId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return models.StandMember{}, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return models.StandMember{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	member, err := s.standRepository.FindMemberByUserId(userId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return models.StandMember{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if member.StandId != stand.Id {
+		return models.StandMember{}, errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not a member of the stand"),
+		}
+	}
+
+	return member, nil
+}
@@ -0,0 +1,63 @@
+package kermesse
+
+import (
+	"database/sql"
+	goErrors "errors"
+	"time"
+
+	"standmaster/internal/models"
+	"standmaster/pkg/errors"
+)
+
+// CheckOpen returns an error keyed KermesseClosed when the kermesse isn't
+// open at the given time, either not running at that time or outside of its
+// planned start and end. A kermesse closed since then is accepted when it was
+// running at that time, so the sales recorded offline can still be synced.
+func CheckOpen(repository KermesseRepository, id int, at time.Time) error {
+	kermesse, err := repository.FindById(id)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	// the history is checked for a running kermesse too, it may have been
+	// run after the given time
+	isRunning := false
+	if kermesse.Status == models.KermesseStatusRunning || IsOver(kermesse.Status) {
+		isRunning, err = repository.WasRunning(id, at)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+	}
+	if !isRunning {
+		return errors.CustomError{
+			Key: errors.KermesseClosed,
+			Err: goErrors.New("kermesse is not running"),
+		}
+	}
+	if kermesse.StartsAt != nil && at.Before(*kermesse.StartsAt) {
+		return errors.CustomError{
+			Key: errors.KermesseClosed,
+			Err: goErrors.New("kermesse is not started yet"),
+		}
+	}
+	if kermesse.EndsAt != nil && !at.Before(*kermesse.EndsAt) {
+		return errors.CustomError{
+			Key: errors.KermesseClosed,
+			Err: goErrors.New("kermesse is over"),
+		}
+	}
+
+	return nil
+}
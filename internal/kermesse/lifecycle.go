@@ -0,0 +1,32 @@
+package kermesse
+
+import "standmaster/internal/models"
+
+// transitions lists the states a kermesse can be moved to from each state.
+var transitions = map[string][]string{
+	models.KermesseStatusDraft:     {models.KermesseStatusPublished},
+	models.KermesseStatusPublished: {models.KermesseStatusDraft, models.KermesseStatusRunning, models.KermesseStatusClosed},
+	models.KermesseStatusRunning:   {models.KermesseStatusClosed},
+	models.KermesseStatusClosed:    {models.KermesseStatusArchived},
+	models.KermesseStatusArchived:  {models.KermesseStatusClosed},
+}
+
+func IsStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+func CanTransition(from string, to string) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsOver tells if the kermesse is closed, whether archived or not.
+func IsOver(status string) bool {
+	return status == models.KermesseStatusClosed || status == models.KermesseStatusArchived
+}
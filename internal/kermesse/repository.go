@@ -1,29 +1,43 @@
 package kermesse
 
 import (
+	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"standmaster/internal/models"
+	"standmaster/third_party/database"
 )
 
 type KermesseRepository interface {
+	WithTx(tx *sqlx.Tx) KermesseRepository
 	FindAll(filters map[string]interface{}) ([]models.Kermesse, error)
 	FindUsersInvite(id int) ([]models.UserBasic, error)
 	FindById(id int) (models.Kermesse, error)
 	Stats(id int, filters map[string]interface{}) (models.KermesseStats, error)
-	Create(input map[string]interface{}) error
+	Create(input map[string]interface{}) (int, error)
 	Update(id int, input map[string]interface{}) error
-	End(id int) error
 	CanEnd(id int) (bool, error)
-
+	UpdateImage(id int, image models.Image) error
+	UpdateStatus(id int, from string, to string) error
+	UpdateStartsAt(id int, startsAt time.Time) error
+	WasRunning(id int, at time.Time) (bool, error)
+	FindAllStatusChanges(id int) ([]models.KermesseStatusChange, error)
+	CreateStatusChange(input map[string]interface{}) error
+	FindAllDueToRun() ([]models.Kermesse, error)
+	FindAllDueToClose() ([]models.Kermesse, error)
+	SetEndBlocked(id int) error
+
+	HasUser(id int, userId int) (bool, error)
+	HasStand(id int, standId int) (bool, error)
 	AddUser(input map[string]interface{}) error
 	CanAddStand(standId int) (bool, error)
 	AddStand(input map[string]interface{}) error
 }
 
 type Repository struct {
-	db *sqlx.DB
+	db database.Queryer
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
@@ -32,6 +46,12 @@ func NewRepository(db *sqlx.DB) *Repository {
 	}
 }
 
+func (s *Repository) WithTx(tx *sqlx.Tx) KermesseRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
 func (s *Repository) FindAll(filters map[string]interface{}) ([]models.Kermesse, error) {
 	kermesses := []models.Kermesse{}
 	query := `
@@ -40,7 +60,12 @@ func (s *Repository) FindAll(filters map[string]interface{}) ([]models.Kermesse,
 			k.user_id AS user_id,
 			k.name AS name,
 			k.description AS description,
-			k.status AS status
+			k.status AS status,
+			k.image_url AS image_url,
+			k.thumbnail_url AS thumbnail_url,
+			k.starts_at AS starts_at,
+			k.ends_at AS ends_at,
+			k.end_blocked_at AS end_blocked_at
 		FROM kermesses k
 		FULL OUTER JOIN kermesses_users ku ON k.id = ku.kermesse_id
 		FULL OUTER JOIN kermesses_stands ks ON k.id = ks.kermesse_id
@@ -57,7 +82,15 @@ func (s *Repository) FindAll(filters map[string]interface{}) ([]models.Kermesse,
 		query += fmt.Sprintf(" AND ku.user_id = %v", filters["child_id"])
 	}
 	if filters["stand_holder_id"] != nil {
-		query += fmt.Sprintf(" AND ks.stand_id IS NOT NULL AND s.user_id = %v", filters["stand_holder_id"])
+		query += fmt.Sprintf(" AND ks.stand_id IS NOT NULL AND s.id IN (SELECT stand_id FROM stand_members WHERE user_id = %v)", filters["stand_holder_id"])
+	}
+	// the statuses are checked by the service
+	if filters["status"] != nil {
+		query += fmt.Sprintf(" AND k.status = '%v'", filters["status"])
+	}
+	excluded, _ := filters["exclude_statuses"].([]string)
+	for _, status := range excluded {
+		query += fmt.Sprintf(" AND k.status <> '%v'", status)
 	}
 	err := s.db.Select(&kermesses, query)
 
@@ -122,12 +155,12 @@ func (s *Repository) Stats(id int, filters map[string]interface{}) (models.Kerme
 			SELECT COUNT(*)
 			FROM interactions i
 			JOIN stands s ON i.stand_id = s.id
-			WHERE i.kermesse_id=$1
+			WHERE i.kermesse_id=$1 AND i.status NOT IN ($2, $3, $4)
 		`
 		if filters["stand_holder_id"] != nil {
-			query += fmt.Sprintf(" AND s.user_id=%v", filters["stand_holder_id"])
+			query += fmt.Sprintf(" AND s.id IN (SELECT stand_id FROM stand_members WHERE user_id=%v)", filters["stand_holder_id"])
 		}
-		err := s.db.Get(&interactionCount, query, id)
+		err := s.db.Get(&interactionCount, query, id, models.InteractionStatusPendingApproval, models.InteractionStatusRejected, models.InteractionStatusExpired)
 		if err != nil {
 			return models.KermesseStats{}, err
 		}
@@ -136,15 +169,15 @@ func (s *Repository) Stats(id int, filters map[string]interface{}) (models.Kerme
 	interactionIncome := 0
 	if filters["organizer_id"] != nil || filters["stand_holder_id"] != nil {
 		query := `
-			SELECT COALESCE(SUM(i.credit), 0)
+			SELECT COALESCE(SUM(i.credit - i.credit * i.refunded_quantity / i.quantity), 0)
 			FROM interactions i
 			JOIN stands s ON i.stand_id = s.id
-			WHERE i.kermesse_id=$1
+			WHERE i.kermesse_id=$1 AND i.status NOT IN ($2, $3, $4)
 		`
 		if filters["stand_holder_id"] != nil {
-			query += fmt.Sprintf(" AND s.user_id=%v", filters["stand_holder_id"])
+			query += fmt.Sprintf(" AND s.id IN (SELECT stand_id FROM stand_members WHERE user_id=%v)", filters["stand_holder_id"])
 		}
-		err := s.db.Get(&interactionIncome, query, id)
+		err := s.db.Get(&interactionIncome, query, id, models.InteractionStatusPendingApproval, models.InteractionStatusRejected, models.InteractionStatusExpired)
 		if err != nil {
 			return models.KermesseStats{}, err
 		}
@@ -189,16 +222,27 @@ func (s *Repository) FindById(id int) (models.Kermesse, error) {
 	return kermesse, err
 }
 
-func (s *Repository) Create(input map[string]interface{}) error {
-	query := "INSERT INTO kermesses (user_id, name, description) VALUES ($1, $2, $3)"
-	_, err := s.db.Exec(query, input["user_id"], input["name"], input["description"])
+func (s *Repository) Create(input map[string]interface{}) (int, error) {
+	var id int
+	query := "INSERT INTO kermesses (user_id, name, description, status, starts_at, ends_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+	err := s.db.QueryRow(query, input["user_id"], input["name"], input["description"], input["status"], input["starts_at"], input["ends_at"]).Scan(&id)
 
-	return err
+	return id, err
 }
 
+// Update changes the kermesse, moving ends_at lets the organizer be told
+// again if the kermesse can't be closed at the new time.
 func (s *Repository) Update(id int, input map[string]interface{}) error {
-	query := "UPDATE kermesses SET name=$1, description=$2 WHERE id=$3"
-	_, err := s.db.Exec(query, input["name"], input["description"], id)
+	query := `
+		UPDATE kermesses SET
+			name=$1,
+			description=$2,
+			starts_at=$3,
+			ends_at=$4,
+			end_blocked_at=CASE WHEN ends_at IS DISTINCT FROM $4 THEN NULL ELSE end_blocked_at END
+		WHERE id=$5
+	`
+	_, err := s.db.Exec(query, input["name"], input["description"], input["starts_at"], input["ends_at"], id)
 
 	return err
 }
@@ -211,13 +255,114 @@ func (s *Repository) CanEnd(id int) (bool, error) {
 	return !isTrue, err
 }
 
-func (s *Repository) End(id int) error {
-	query := "UPDATE kermesses SET status=$1 WHERE id=$2"
-	_, err := s.db.Exec(query, models.KermesseStatusEnded, id)
+// UpdateStatus moves the kermesse from a state to another, sql.ErrNoRows is
+// returned when it was moved meanwhile.
+func (s *Repository) UpdateStatus(id int, from string, to string) error {
+	query := "UPDATE kermesses SET status=$1 WHERE id=$2 AND status=$3"
+	result, err := s.db.Exec(query, to, id, from)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (s *Repository) UpdateStartsAt(id int, startsAt time.Time) error {
+	query := "UPDATE kermesses SET starts_at=$1 WHERE id=$2"
+	_, err := s.db.Exec(query, startsAt, id)
+
+	return err
+}
+
+// WasRunning tells if the kermesse was running at the given time according to
+// its history.
+func (s *Repository) WasRunning(id int, at time.Time) (bool, error) {
+	var isTrue bool
+	query := `
+		SELECT COALESCE((
+			SELECT to_status = $2
+			FROM kermesse_status_changes
+			WHERE kermesse_id = $1 AND created_at <= $3
+			ORDER BY created_at DESC, id DESC
+			LIMIT 1
+		), FALSE) AS is_true
+	`
+	err := s.db.QueryRow(query, id, models.KermesseStatusRunning, at).Scan(&isTrue)
+
+	return isTrue, err
+}
+
+func (s *Repository) FindAllStatusChanges(id int) ([]models.KermesseStatusChange, error) {
+	changes := []models.KermesseStatusChange{}
+	query := "SELECT * FROM kermesse_status_changes WHERE kermesse_id=$1 ORDER BY created_at, id"
+	err := s.db.Select(&changes, query, id)
+
+	return changes, err
+}
+
+func (s *Repository) CreateStatusChange(input map[string]interface{}) error {
+	query := "INSERT INTO kermesse_status_changes (kermesse_id, from_status, to_status, user_id) VALUES ($1, $2, $3, $4)"
+	_, err := s.db.Exec(query, input["kermesse_id"], input["from_status"], input["to_status"], input["user_id"])
 
 	return err
 }
 
+// FindAllDueToRun lists the published kermesses whose start time has come.
+func (s *Repository) FindAllDueToRun() ([]models.Kermesse, error) {
+	kermesses := []models.Kermesse{}
+	query := "SELECT * FROM kermesses WHERE status=$1 AND starts_at <= CURRENT_TIMESTAMP ORDER BY starts_at"
+	err := s.db.Select(&kermesses, query, models.KermesseStatusPublished)
+
+	return kermesses, err
+}
+
+// FindAllDueToClose lists the running kermesses whose end time has come.
+func (s *Repository) FindAllDueToClose() ([]models.Kermesse, error) {
+	kermesses := []models.Kermesse{}
+	query := "SELECT * FROM kermesses WHERE status=$1 AND ends_at <= CURRENT_TIMESTAMP ORDER BY ends_at"
+	err := s.db.Select(&kermesses, query, models.KermesseStatusRunning)
+
+	return kermesses, err
+}
+
+func (s *Repository) SetEndBlocked(id int) error {
+	query := "UPDATE kermesses SET end_blocked_at=CURRENT_TIMESTAMP WHERE id=$1"
+	_, err := s.db.Exec(query, id)
+
+	return err
+}
+
+func (s *Repository) UpdateImage(id int, image models.Image) error {
+	query := "UPDATE kermesses SET image_url=$1, thumbnail_url=$2 WHERE id=$3"
+	_, err := s.db.Exec(query, image.URL, image.ThumbnailURL, id)
+
+	return err
+}
+
+func (s *Repository) HasUser(id int, userId int) (bool, error) {
+	var isTrue bool
+	query := "SELECT EXISTS ( SELECT 1 FROM kermesses_users WHERE kermesse_id = $1 AND user_id = $2 ) AS is_true"
+	err := s.db.QueryRow(query, id, userId).Scan(&isTrue)
+
+	return isTrue, err
+}
+
+func (s *Repository) HasStand(id int, standId int) (bool, error) {
+	var isTrue bool
+	query := "SELECT EXISTS ( SELECT 1 FROM kermesses_stands WHERE kermesse_id = $1 AND stand_id = $2 ) AS is_true"
+	err := s.db.QueryRow(query, id, standId).Scan(&isTrue)
+
+	return isTrue, err
+}
+
 func (s *Repository) AddUser(input map[string]interface{}) error {
 	query := "INSERT INTO kermesses_users (kermesse_id, user_id) VALUES ($1, $2)"
 	_, err := s.db.Exec(query, input["kermesse_id"], input["user_id"])
@@ -232,10 +377,10 @@ func (s *Repository) CanAddStand(standId int) (bool, error) {
 			SELECT 1
 			FROM kermesses_stands ks
   		JOIN kermesses k ON ks.kermesse_id = k.id
-  		WHERE ks.stand_id = $1 AND k.status = $2
+  		WHERE ks.stand_id = $1 AND k.status NOT IN ($2, $3)
 		) AS is_associated
  	`
-	err := s.db.QueryRow(query, standId, models.KermesseStatusStarted).Scan(&isTrue)
+	err := s.db.QueryRow(query, standId, models.KermesseStatusClosed, models.KermesseStatusArchived).Scan(&isTrue)
 
 	return !isTrue, err
 }
@@ -4,38 +4,63 @@ import (
 	"context"
 	"database/sql"
 	goErrors "errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
 
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/media"
 	"standmaster/internal/models"
+	"standmaster/internal/notification"
 	"standmaster/internal/user"
 	"standmaster/pkg/errors"
 	"standmaster/pkg/utils"
+	"standmaster/third_party/database"
 )
 
+// errTombolaOpen rolls back the closing of a kermesse whose tombola is open.
+var errTombolaOpen = goErrors.New("tombola is open")
+
 type KermesseService interface {
-	GetAll(ctx context.Context) ([]models.Kermesse, error)
+	GetAll(ctx context.Context, params map[string]interface{}) ([]models.Kermesse, error)
 	GetUsersInvite(ctx context.Context, id int) ([]models.UserBasic, error)
 	Get(ctx context.Context, id int) (models.KermesseWithStats, error)
 	Create(ctx context.Context, input map[string]interface{}) error
 	Update(ctx context.Context, id int, input map[string]interface{}) error
 	End(ctx context.Context, id int) error
+	SetStatus(ctx context.Context, id int, input map[string]interface{}) error
+	GetHistory(ctx context.Context, id int) ([]models.KermesseStatusChange, error)
+	UploadImage(ctx context.Context, id int, file io.Reader) (models.Kermesse, error)
 
 	AddUser(ctx context.Context, input map[string]interface{}) error
 	AddStand(ctx context.Context, input map[string]interface{}) error
+
+	RunDue() error
+	CloseDue() error
 }
 
 type Service struct {
 	repository     KermesseRepository
 	userRepository user.UserRepository
+	mediaService   media.MediaService
+	notifier       notification.Notifier
+	transactor     database.Transactor
 }
 
-func NewService(repository KermesseRepository, userRepository user.UserRepository) *Service {
+func NewService(repository KermesseRepository, userRepository user.UserRepository, mediaService media.MediaService, notifier notification.Notifier, transactor database.Transactor) *Service {
 	return &Service{
 		repository:     repository,
 		userRepository: userRepository,
+		mediaService:   mediaService,
+		notifier:       notifier,
+		transactor:     transactor,
 	}
 }
 
-func (s *Service) GetAll(ctx context.Context) ([]models.Kermesse, error) {
+// GetAll lists the kermesses of the user, the archived ones only when asked
+// with status=ARCHIVED. Only the organizer sees the drafts.
+func (s *Service) GetAll(ctx context.Context, params map[string]interface{}) ([]models.Kermesse, error) {
 	userId, ok := ctx.Value(models.UserIDKey).(int)
 	if !ok {
 		return nil, errors.CustomError{
@@ -62,6 +87,23 @@ func (s *Service) GetAll(ctx context.Context) ([]models.Kermesse, error) {
 		filters["stand_holder_id"] = userId
 	}
 
+	excluded := []string{models.KermesseStatusArchived}
+	if userRole != models.UserRoleOrganizer {
+		excluded = append(excluded, models.KermesseStatusDraft)
+	}
+	if params["status"] != nil {
+		status, _ := params["status"].(string)
+		if !IsStatus(status) {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("status is not valid"),
+			}
+		}
+		filters["status"] = status
+		excluded = excluded[1:]
+	}
+	filters["exclude_statuses"] = excluded
+
 	kermesses, err := s.repository.FindAll(filters)
 	if err != nil {
 		return nil, errors.CustomError{
@@ -115,6 +157,13 @@ func (s *Service) Get(ctx context.Context, id int) (models.KermesseWithStats, er
 		}
 	}
 
+	if kermesse.Status == models.KermesseStatusDraft && kermesse.UserId != userId {
+		return models.KermesseWithStats{}, errors.CustomError{
+			Key: errors.NotFound,
+			Err: sql.ErrNoRows,
+		}
+	}
+
 	filters := map[string]interface{}{}
 	if userRole == models.UserRoleOrganizer {
 		filters["organizer_id"] = userId
@@ -140,6 +189,10 @@ func (s *Service) Get(ctx context.Context, id int) (models.KermesseWithStats, er
 		Name:              kermesse.Name,
 		Description:       kermesse.Description,
 		Status:            kermesse.Status,
+		ImageURL:          kermesse.ImageURL,
+		ThumbnailURL:      kermesse.ThumbnailURL,
+		StartsAt:          kermesse.StartsAt,
+		EndsAt:            kermesse.EndsAt,
 		StandCount:        stats.StandCount,
 		TombolaCount:      stats.TombolaCount,
 		UserCount:         stats.UserCount,
@@ -151,6 +204,8 @@ func (s *Service) Get(ctx context.Context, id int) (models.KermesseWithStats, er
 	return kermesseWithStats, nil
 }
 
+// Create drafts a kermesse, once published it runs from starts_at, or when
+// the organizer starts it, until ends_at or until the organizer closes it.
 func (s *Service) Create(ctx context.Context, input map[string]interface{}) error {
 	userId, ok := ctx.Value(models.UserIDKey).(int)
 	if !ok {
@@ -161,12 +216,41 @@ func (s *Service) Create(ctx context.Context, input map[string]interface{}) erro
 	}
 	input["user_id"] = userId
 
-	err := s.repository.Create(input)
-	if err != nil {
-		return errors.CustomError{
-			Key: errors.InternalServerError,
-			Err: err,
+	kermesse := models.Kermesse{}
+	if err := parseSchedule(input, &kermesse); err != nil {
+		return err
+	}
+	input["status"] = models.KermesseStatusDraft
+	input["starts_at"] = kermesse.StartsAt
+	input["ends_at"] = kermesse.EndsAt
+
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+
+		id, err := repository.Create(input)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
 		}
+
+		err = repository.CreateStatusChange(map[string]interface{}{
+			"kermesse_id": id,
+			"to_status":   models.KermesseStatusDraft,
+			"user_id":     userId,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
 	}
 
 	return nil
@@ -187,10 +271,10 @@ func (s *Service) Update(ctx context.Context, id int, input map[string]interface
 		}
 	}
 
-	if kermesse.Status == models.KermesseStatusEnded {
+	if IsOver(kermesse.Status) {
 		return errors.CustomError{
 			Key: errors.BadRequest,
-			Err: goErrors.New("kermesse is already ended"),
+			Err: goErrors.New("kermesse is closed"),
 		}
 	}
 
@@ -208,6 +292,12 @@ func (s *Service) Update(ctx context.Context, id int, input map[string]interface
 		}
 	}
 
+	if err := parseSchedule(input, &kermesse); err != nil {
+		return err
+	}
+	input["starts_at"] = kermesse.StartsAt
+	input["ends_at"] = kermesse.EndsAt
+
 	err = s.repository.Update(id, input)
 	if err != nil {
 		return errors.CustomError{
@@ -219,64 +309,244 @@ func (s *Service) Update(ctx context.Context, id int, input map[string]interface
 	return nil
 }
 
-func (s *Service) End(ctx context.Context, id int) error {
+// UploadImage lets the organizer replace the picture of the kermesse.
+func (s *Service) UploadImage(ctx context.Context, id int, file io.Reader) (models.Kermesse, error) {
 	kermesse, err := s.repository.FindById(id)
 	if err != nil {
 		if goErrors.Is(err, sql.ErrNoRows) {
-			return errors.CustomError{
+			return kermesse, errors.CustomError{
 				Key: errors.NotFound,
 				Err: err,
 			}
 		}
-		return errors.CustomError{
+		return kermesse, errors.CustomError{
 			Key: errors.InternalServerError,
 			Err: err,
 		}
 	}
 
-	if kermesse.Status == models.KermesseStatusEnded {
-		return errors.CustomError{
-			Key: errors.BadRequest,
-			Err: goErrors.New("kermesse is already ended"),
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return kermesse, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+	if kermesse.UserId != userId {
+		return kermesse, errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("forbidden"),
 		}
 	}
 
-	canEnd, err := s.repository.CanEnd(id)
+	image, err := s.mediaService.SaveImage(fmt.Sprintf("kermesses/%d", id), file)
 	if err != nil {
-		return errors.CustomError{
+		return kermesse, err
+	}
+
+	err = s.repository.UpdateImage(id, image)
+	if err != nil {
+		s.mediaService.DeleteImage(&image.URL, &image.ThumbnailURL)
+		return kermesse, errors.CustomError{
 			Key: errors.InternalServerError,
 			Err: err,
 		}
 	}
-	if !canEnd {
+	s.mediaService.DeleteImage(kermesse.ImageURL, kermesse.ThumbnailURL)
+
+	kermesse.ImageURL = &image.URL
+	kermesse.ThumbnailURL = &image.ThumbnailURL
+
+	return kermesse, nil
+}
+
+// End lets the organizer close the kermesse.
+func (s *Service) End(ctx context.Context, id int) error {
+	return s.SetStatus(ctx, id, map[string]interface{}{"status": models.KermesseStatusClosed})
+}
+
+// SetStatus lets the organizer move the kermesse to the status given in
+// input, as long as the lifecycle allows it.
+func (s *Service) SetStatus(ctx context.Context, id int, input map[string]interface{}) error {
+	kermesse, err := s.findOwned(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	status, _ := input["status"].(string)
+	if !IsStatus(status) {
 		return errors.CustomError{
 			Key: errors.BadRequest,
-			Err: goErrors.New("kermesse can't be ended"),
+			Err: goErrors.New("status is not valid"),
 		}
 	}
 
-	userId, ok := ctx.Value(models.UserIDKey).(int)
-	if !ok {
-		return errors.CustomError{
-			Key: errors.Unauthorized,
-			Err: goErrors.New("user id not found in context"),
-		}
+	userId := kermesse.UserId
+	isDone, err := s.transition(kermesse, status, &userId)
+	if err != nil {
+		return err
 	}
-	if kermesse.UserId != userId {
+	if !isDone {
 		return errors.CustomError{
-			Key: errors.Forbidden,
-			Err: goErrors.New("forbidden"),
+			Key: errors.BadRequest,
+			Err: goErrors.New("kermesse can't be closed while a tombola is open"),
 		}
 	}
 
-	err = s.repository.End(id)
+	return nil
+}
+
+// GetHistory lets the organizer see the state changes of the kermesse.
+func (s *Service) GetHistory(ctx context.Context, id int) ([]models.KermesseStatusChange, error) {
+	if _, err := s.findOwned(ctx, id); err != nil {
+		return nil, err
+	}
+
+	changes, err := s.repository.FindAllStatusChanges(id)
 	if err != nil {
-		return errors.CustomError{
+		return nil, errors.CustomError{
 			Key: errors.InternalServerError,
 			Err: err,
 		}
 	}
 
+	return changes, nil
+}
+
+// transition moves the kermesse to status and records the change made by
+// userId, nil for the scheduler. A kermesse run before its planned start
+// starts now. The kermesse isn't closed while one of its tombolas is open,
+// false is then returned.
+func (s *Service) transition(kermesse models.Kermesse, status string, userId *int) (bool, error) {
+	if !CanTransition(kermesse.Status, status) {
+		return false, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: fmt.Errorf("kermesse can't go from %s to %s", kermesse.Status, status),
+		}
+	}
+
+	isDone := true
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+
+		err := repository.UpdateStatus(kermesse.Id, kermesse.Status, status)
+		if err != nil {
+			if goErrors.Is(err, sql.ErrNoRows) {
+				return errors.CustomError{
+					Key: errors.BadRequest,
+					Err: goErrors.New("kermesse status has changed"),
+				}
+			}
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		// checked once the kermesse is locked by the update, the rollback
+		// keeps its status
+		if status == models.KermesseStatusClosed {
+			canEnd, err := repository.CanEnd(kermesse.Id)
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+			if !canEnd {
+				isDone = false
+				return errTombolaOpen
+			}
+		}
+
+		now := time.Now()
+		if status == models.KermesseStatusRunning && kermesse.StartsAt != nil && kermesse.StartsAt.After(now) {
+			if err := repository.UpdateStartsAt(kermesse.Id, now); err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+		}
+
+		err = repository.CreateStatusChange(map[string]interface{}{
+			"kermesse_id": kermesse.Id,
+			"from_status": kermesse.Status,
+			"to_status":   status,
+			"user_id":     userId,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if !isDone {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.FromError(err)
+	}
+
+	return true, nil
+}
+
+// RunDue runs the published kermesses whose start time has come, it's run by
+// the scheduler. A kermesse that can't be run doesn't hold back the others.
+func (s *Service) RunDue() error {
+	kermesses, err := s.repository.FindAllDueToRun()
+	if err != nil {
+		return err
+	}
+
+	for _, kermesse := range kermesses {
+		if _, err := s.transition(kermesse, models.KermesseStatusRunning, nil); err != nil {
+			log.Printf("Error running kermesse %d: %v\n", kermesse.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// CloseDue closes the running kermesses whose end time has come, it's run by
+// the scheduler. The organizer is told once when an open tombola keeps the
+// kermesse from being closed, it is then closed as soon as the tombola is
+// drawn. A kermesse that can't be closed doesn't hold back the others.
+func (s *Service) CloseDue() error {
+	kermesses, err := s.repository.FindAllDueToClose()
+	if err != nil {
+		return err
+	}
+
+	for _, kermesse := range kermesses {
+		isClosed, err := s.transition(kermesse, models.KermesseStatusClosed, nil)
+		if err != nil {
+			log.Printf("Error closing kermesse %d: %v\n", kermesse.Id, err)
+			continue
+		}
+		if isClosed || kermesse.EndBlockedAt != nil {
+			continue
+		}
+
+		err = s.notifier.Notify([]int{kermesse.UserId}, models.Notification{
+			Kind:       models.NotificationKindKermesseEndBlocked,
+			Title:      fmt.Sprintf("Clôture impossible : %s", kermesse.Name),
+			Message:    fmt.Sprintf("La kermesse %s n'a pas pu être clôturée car une tombola est encore ouverte. Elle le sera dès que la tombola sera tirée.", kermesse.Name),
+			KermesseId: &kermesse.Id,
+		})
+		if err != nil {
+			log.Printf("Error notifying the organizer of kermesse %d: %v\n", kermesse.Id, err)
+			continue
+		}
+
+		if err := s.repository.SetEndBlocked(kermesse.Id); err != nil {
+			log.Printf("Error closing kermesse %d: %v\n", kermesse.Id, err)
+		}
+	}
+
 	return nil
 }
 
@@ -295,10 +565,10 @@ func (s *Service) AddUser(ctx context.Context, input map[string]interface{}) err
 		}
 	}
 
-	if kermesse.Status == models.KermesseStatusEnded {
+	if IsOver(kermesse.Status) {
 		return errors.CustomError{
 			Key: errors.BadRequest,
-			Err: goErrors.New("kermesse is already ended"),
+			Err: goErrors.New("kermesse is closed"),
 		}
 	}
 
@@ -382,10 +652,10 @@ func (s *Service) AddStand(ctx context.Context, input map[string]interface{}) er
 		}
 	}
 
-	if kermesse.Status == models.KermesseStatusEnded {
+	if IsOver(kermesse.Status) {
 		return errors.CustomError{
 			Key: errors.BadRequest,
-			Err: goErrors.New("kermesse is already ended"),
+			Err: goErrors.New("kermesse is closed"),
 		}
 	}
 
@@ -434,3 +704,77 @@ func (s *Service) AddStand(ctx context.Context, input map[string]interface{}) er
 
 	return nil
 }
+
+// parseSchedule sets the planned start and end given in input on kermesse,
+// the start can't be moved once the kermesse is running.
+func parseSchedule(input map[string]interface{}, kermesse *models.Kermesse) error {
+	isNew := kermesse.Id == 0
+
+	for key, value := range map[string]**time.Time{"starts_at": &kermesse.StartsAt, "ends_at": &kermesse.EndsAt} {
+		if input[key] == nil {
+			continue
+		}
+		text, _ := input[key].(string)
+		date, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New(key + " is not a valid date"),
+			}
+		}
+		if key == "starts_at" && !isNew && kermesse.Status != models.KermesseStatusDraft && kermesse.Status != models.KermesseStatusPublished {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("kermesse is already running"),
+			}
+		}
+		*value = &date
+	}
+
+	if kermesse.StartsAt != nil && kermesse.EndsAt != nil && !kermesse.EndsAt.After(*kermesse.StartsAt) {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("ends_at must be after starts_at"),
+		}
+	}
+	if input["ends_at"] != nil && !kermesse.EndsAt.After(time.Now()) {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("ends_at is in the past"),
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) findOwned(ctx context.Context, id int) (models.Kermesse, error) {
+	kermesse, err := s.repository.FindById(id)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return kermesse, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return kermesse, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return kermesse, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+	if kermesse.UserId != userId {
+		return kermesse, errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("forbidden"),
+		}
+	}
+
+	return kermesse, nil
+}
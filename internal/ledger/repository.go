@@ -0,0 +1,74 @@
+package ledger
+
+import (
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+	"standmaster/third_party/database"
+)
+
+type LedgerRepository interface {
+	WithTx(tx *sqlx.Tx) LedgerRepository
+	FindAllByUserId(userId int) ([]models.CreditTransaction, error)
+	Create(input map[string]interface{}) error
+}
+
+type Repository struct {
+	db database.Queryer
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) WithTx(tx *sqlx.Tx) LedgerRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
+func (s *Repository) FindAllByUserId(userId int) ([]models.CreditTransaction, error) {
+	transactions := []models.CreditTransaction{}
+	query := `
+		SELECT *
+		FROM credit_transactions
+		WHERE debit_user_id=$1 OR credit_user_id=$1
+		ORDER BY created_at DESC, id DESC
+	`
+	err := s.db.Select(&transactions, query, userId)
+
+	return transactions, err
+}
+
+// Create appends an entry to the ledger and applies it to the cached
+// balance of both accounts, users.credit is never written elsewhere. It must
+// run in a transaction to keep the entry and the balances in step.
+func (s *Repository) Create(input map[string]interface{}) error {
+	query := `
+		INSERT INTO credit_transactions (debit_user_id, credit_user_id, amount, kind, interaction_id, ticket_id, stripe_session_id, cash_topup_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := s.db.Exec(query, input["debit_user_id"], input["credit_user_id"], input["amount"], input["kind"], input["interaction_id"], input["ticket_id"], input["stripe_session_id"], input["cash_topup_id"])
+	if err != nil {
+		return err
+	}
+
+	if input["debit_user_id"] != nil {
+		query = "UPDATE users SET credit=credit-$1 WHERE id=$2"
+		_, err = s.db.Exec(query, input["amount"], input["debit_user_id"])
+		if err != nil {
+			return err
+		}
+	}
+
+	if input["credit_user_id"] != nil {
+		query = "UPDATE users SET credit=credit+$1 WHERE id=$2"
+		_, err = s.db.Exec(query, input["amount"], input["credit_user_id"])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package media
+
+import (
+	"bytes"
+	goErrors "errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"standmaster/internal/models"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/imaging"
+	"standmaster/third_party/storage"
+)
+
+// ThumbnailSize is the largest side of the thumbnails, in pixels.
+const ThumbnailSize = 320
+
+type MediaService interface {
+	SaveImage(folder string, file io.Reader) (models.Image, error)
+	DeleteImage(url *string, thumbnailURL *string)
+}
+
+type Service struct {
+	storage storage.Storage
+}
+
+func NewService(storage storage.Storage) *Service {
+	return &Service{
+		storage: storage,
+	}
+}
+
+// SaveImage checks the uploaded file is an image no larger than the upload
+// limit, then stores it with its thumbnail under folder.
+func (s *Service) SaveImage(folder string, file io.Reader) (models.Image, error) {
+	maxSize, err := maxUploadSize()
+	if err != nil {
+		return models.Image{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return models.Image{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+	if int64(len(data)) > maxSize {
+		return models.Image{}, errors.CustomError{
+			Key: errors.FileTooLarge,
+			Err: fmt.Errorf("image is larger than %d bytes", maxSize),
+		}
+	}
+
+	contentType, err := imaging.Sniff(data)
+	if err != nil {
+		return models.Image{}, errors.CustomError{
+			Key: errors.UnsupportedMediaType,
+			Err: err,
+		}
+	}
+
+	thumbnail, thumbnailType, err := imaging.Thumbnail(data, ThumbnailSize)
+	if err != nil {
+		if goErrors.Is(err, imaging.ErrTooManyPixels) {
+			return models.Image{}, errors.CustomError{
+				Key: errors.FileTooLarge,
+				Err: err,
+			}
+		}
+		return models.Image{}, errors.CustomError{
+			Key: errors.UnsupportedMediaType,
+			Err: err,
+		}
+	}
+
+	name := strconv.FormatInt(time.Now().UnixNano(), 10)
+	key := fmt.Sprintf("%s/%s%s", folder, name, extension(contentType))
+	thumbnailKey := fmt.Sprintf("%s/%s_thumbnail%s", folder, name, extension(thumbnailType))
+
+	if err := s.storage.Put(key, bytes.NewReader(data), contentType); err != nil {
+		return models.Image{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if err := s.storage.Put(thumbnailKey, bytes.NewReader(thumbnail), thumbnailType); err != nil {
+		s.delete(key)
+		return models.Image{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return models.Image{
+		URL:          s.storage.URL(key),
+		ThumbnailURL: s.storage.URL(thumbnailKey),
+	}, nil
+}
+
+// DeleteImage removes a replaced image and its thumbnail, failures are only
+// logged as the new image is already saved.
+func (s *Service) DeleteImage(url *string, thumbnailURL *string) {
+	for _, value := range []*string{url, thumbnailURL} {
+		if value == nil {
+			continue
+		}
+		if key, ok := s.storage.Key(*value); ok {
+			s.delete(key)
+		}
+	}
+}
+
+func (s *Service) delete(key string) {
+	if err := s.storage.Delete(key); err != nil {
+		log.Printf("Error deleting %s: %v\n", key, err)
+	}
+}
+
+func extension(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	}
+
+	return ""
+}
+
+// maxUploadSize returns the upload limit in bytes, UPLOAD_MAX_SIZE_MB
+// defaults to 5.
+func maxUploadSize() (int64, error) {
+	value := os.Getenv("UPLOAD_MAX_SIZE_MB")
+	if value == "" {
+		return 5 << 20, nil
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return n << 20, nil
+}
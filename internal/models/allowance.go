@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	AllowanceTypeInterval      string = "INTERVAL"
+	AllowanceTypeKermesseStart string = "KERMESSE_START"
+
+	AllowanceRunStatusSucceeded string = "SUCCEEDED"
+	AllowanceRunStatusFailed    string = "FAILED"
+)
+
+// Allowance is a transfer from a parent to a child paid by the scheduler,
+// every IntervalMinutes or once when the kermesse starts.
+type Allowance struct {
+	Id              int       `json:"id" db:"id"`
+	ParentId        int       `json:"parent_id" db:"parent_id"`
+	ChildId         int       `json:"child_id" db:"child_id"`
+	KermesseId      int       `json:"kermesse_id" db:"kermesse_id"`
+	Type            string    `json:"type" db:"type"`
+	Amount          int       `json:"amount" db:"amount"`
+	IntervalMinutes *int      `json:"interval_minutes" db:"interval_minutes"`
+	IsActive        bool      `json:"is_active" db:"is_active"`
+	NextRunAt       time.Time `json:"next_run_at" db:"next_run_at"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+type AllowanceRun struct {
+	Id          int       `json:"id" db:"id"`
+	AllowanceId int       `json:"allowance_id" db:"allowance_id"`
+	Amount      int       `json:"amount" db:"amount"`
+	Status      string    `json:"status" db:"status"`
+	Error       *string   `json:"error" db:"error"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
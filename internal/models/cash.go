@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// CashTopUp is credit bought with cash at the organizer desk of a kermesse,
+// Amount is in cents.
+type CashTopUp struct {
+	Id            int       `json:"id" db:"id"`
+	KermesseId    int       `json:"kermesse_id" db:"kermesse_id"`
+	UserId        int       `json:"user_id" db:"user_id"`
+	OperatorId    int       `json:"operator_id" db:"operator_id"`
+	Amount        int       `json:"amount" db:"amount"`
+	Credit        int       `json:"credit" db:"credit"`
+	ReceiptNumber string    `json:"receipt_number" db:"receipt_number"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// CashReport sums up the cash top-ups an operator took over a day.
+type CashReport struct {
+	OperatorId   int         `json:"operator_id"`
+	OperatorName string      `json:"operator_name"`
+	Count        int         `json:"count"`
+	Amount       int         `json:"amount"`
+	Credit       int         `json:"credit"`
+	TopUps       []CashTopUp `json:"topups"`
+}
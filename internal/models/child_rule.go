@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	ChildRuleTypeDailyLimit        string = "DAILY_LIMIT"
+	ChildRuleTypeKermesseLimit     string = "KERMESSE_LIMIT"
+	ChildRuleTypeMaxPurchase       string = "MAX_PURCHASE"
+	ChildRuleTypeBlockedStandType  string = "BLOCKED_STAND_TYPE"
+	ChildRuleTypeBlockedStand      string = "BLOCKED_STAND"
+	ChildRuleTypeApprovalThreshold string = "APPROVAL_THRESHOLD"
+)
+
+// ChildRule restricts what a child can spend: limits in credits use Value,
+// BLOCKED_STAND_TYPE uses StandType and BLOCKED_STAND uses StandId.
+type ChildRule struct {
+	Id        int       `json:"id" db:"id"`
+	ChildId   int       `json:"child_id" db:"child_id"`
+	Type      string    `json:"type" db:"type"`
+	Value     *int      `json:"value" db:"value"`
+	StandType *string   `json:"stand_type" db:"stand_type"`
+	StandId   *int      `json:"stand_id" db:"stand_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
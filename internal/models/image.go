@@ -0,0 +1,7 @@
+package models
+
+// Image is an uploaded picture and its thumbnail.
+type Image struct {
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
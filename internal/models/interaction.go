@@ -6,8 +6,12 @@ const (
 	InteractionTypeConsumption string = "CONSUMPTION"
 	InteractionTypeActivity    string = "ACTIVITY"
 
-	InteractionStatusStarted string = "STARTED"
-	InteractionStatusEnded   string = "ENDED"
+	InteractionStatusStarted         string = "STARTED"
+	InteractionStatusEnded           string = "ENDED"
+	InteractionStatusRefunded        string = "REFUNDED"
+	InteractionStatusPendingApproval string = "PENDING_APPROVAL"
+	InteractionStatusRejected        string = "REJECTED"
+	InteractionStatusExpired         string = "EXPIRED"
 )
 
 type InteractionUser struct {
@@ -32,25 +36,53 @@ type InteractionKermesse struct {
 	Status      string `json:"status" db:"status"`
 }
 
+// InteractionItem is a line of the cart of an interaction, Price is the unit
+// price of the product when it was bought.
+type InteractionItem struct {
+	Id            int    `json:"id" db:"id"`
+	InteractionId int    `json:"interaction_id" db:"interaction_id"`
+	ProductId     int    `json:"product_id" db:"product_id"`
+	Name          string `json:"name" db:"name"`
+	Price         int    `json:"price" db:"price"`
+	Quantity      int    `json:"quantity" db:"quantity"`
+}
+
 type Interaction struct {
-	Id        int                 `json:"id" db:"id"`
-	Type      string              `json:"type" db:"type"`
-	Status    string              `json:"status" db:"status"`
-	Credit    int                 `json:"credit" db:"credit"`
-	Point     int                 `json:"point" db:"point"`
-	CreatedAt time.Time           `json:"created_at" db:"created_at"`
-	User      InteractionUser     `json:"user" db:"user"`
-	Stand     InteractionStand    `json:"stand" db:"stand"`
-	Kermesse  InteractionKermesse `json:"kermesse" db:"kermesse"`
+	Id                int                 `json:"id" db:"id"`
+	Type              string              `json:"type" db:"type"`
+	Status            string              `json:"status" db:"status"`
+	Credit            int                 `json:"credit" db:"credit"`
+	Quantity          int                 `json:"quantity" db:"quantity"`
+	RefundedQuantity  int                 `json:"refunded_quantity" db:"refunded_quantity"`
+	Point             int                 `json:"point" db:"point"`
+	Tier              *string             `json:"tier" db:"tier"`
+	PromotionId       *int                `json:"promotion_id" db:"promotion_id"`
+	Discount          int                 `json:"discount" db:"discount"`
+	PromotionQuantity int                 `json:"-" db:"promotion_quantity"`
+	ExpiresAt         *time.Time          `json:"expires_at" db:"expires_at"`
+	CreatedAt         time.Time           `json:"created_at" db:"created_at"`
+	User              InteractionUser     `json:"user" db:"user"`
+	Stand             InteractionStand    `json:"stand" db:"stand"`
+	Kermesse          InteractionKermesse `json:"kermesse" db:"kermesse"`
+	Items             []InteractionItem   `json:"items" db:"-"`
+	Warnings          []string            `json:"warnings,omitempty" db:"-"`
 }
 
 type InteractionBasic struct {
-	Id        int              `json:"id" db:"id"`
-	Type      string           `json:"type" db:"type"`
-	Status    string           `json:"status" db:"status"`
-	Credit    int              `json:"credit" db:"credit"`
-	Point     int              `json:"point" db:"point"`
-	CreatedAt time.Time        `json:"created_at" db:"created_at"`
-	User      InteractionUser  `json:"user" db:"user"`
-	Stand     InteractionStand `json:"stand" db:"stand"`
+	Id                int               `json:"id" db:"id"`
+	Type              string            `json:"type" db:"type"`
+	Status            string            `json:"status" db:"status"`
+	Credit            int               `json:"credit" db:"credit"`
+	Quantity          int               `json:"quantity" db:"quantity"`
+	RefundedQuantity  int               `json:"refunded_quantity" db:"refunded_quantity"`
+	Point             int               `json:"point" db:"point"`
+	Tier              *string           `json:"tier" db:"tier"`
+	PromotionId       *int              `json:"promotion_id" db:"promotion_id"`
+	Discount          int               `json:"discount" db:"discount"`
+	PromotionQuantity int               `json:"-" db:"promotion_quantity"`
+	ExpiresAt         *time.Time        `json:"expires_at" db:"expires_at"`
+	CreatedAt         time.Time         `json:"created_at" db:"created_at"`
+	User              InteractionUser   `json:"user" db:"user"`
+	Stand             InteractionStand  `json:"stand" db:"stand"`
+	Items             []InteractionItem `json:"items" db:"-"`
 }
@@ -1,16 +1,28 @@
 package models
 
+import "time"
+
 const (
-	KermesseStatusStarted string = "STARTED"
-	KermesseStatusEnded   string = "ENDED"
+	KermesseStatusDraft     string = "DRAFT"
+	KermesseStatusPublished string = "PUBLISHED"
+	KermesseStatusRunning   string = "RUNNING"
+	KermesseStatusClosed    string = "CLOSED"
+	KermesseStatusArchived  string = "ARCHIVED"
 )
 
+// Kermesse is an event, a published one with StartsAt and EndsAt is run and
+// closed by the scheduler.
 type Kermesse struct {
-	Id          int    `json:"id" db:"id"`
-	UserId      int    `json:"user_id" db:"user_id"`
-	Name        string `json:"name" db:"name"`
-	Description string `json:"description" db:"description"`
-	Status      string `json:"status" db:"status"`
+	Id           int        `json:"id" db:"id"`
+	UserId       int        `json:"user_id" db:"user_id"`
+	Name         string     `json:"name" db:"name"`
+	Description  string     `json:"description" db:"description"`
+	Status       string     `json:"status" db:"status"`
+	ImageURL     *string    `json:"image_url" db:"image_url"`
+	ThumbnailURL *string    `json:"thumbnail_url" db:"thumbnail_url"`
+	StartsAt     *time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at" db:"ends_at"`
+	EndBlockedAt *time.Time `json:"-" db:"end_blocked_at"`
 }
 
 type KermesseStats struct {
@@ -24,16 +36,31 @@ type KermesseStats struct {
 }
 
 type KermesseWithStats struct {
-	Id                int    `json:"id" db:"id"`
-	UserId            int    `json:"user_id" db:"user_id"`
-	Name              string `json:"name" db:"name"`
-	Description       string `json:"description" db:"description"`
-	Status            string `json:"status" db:"status"`
-	StandCount        int    `json:"stand_count"`
-	TombolaCount      int    `json:"tombola_count"`
-	UserCount         int    `json:"user_count"`
-	InteractionCount  int    `json:"interaction_count"`
-	InteractionIncome int    `json:"interaction_income"`
-	TombolaIncome     int    `json:"tombola_income"`
-	Points            int    `json:"points"`
+	Id                int        `json:"id" db:"id"`
+	UserId            int        `json:"user_id" db:"user_id"`
+	Name              string     `json:"name" db:"name"`
+	Description       string     `json:"description" db:"description"`
+	Status            string     `json:"status" db:"status"`
+	ImageURL          *string    `json:"image_url" db:"image_url"`
+	ThumbnailURL      *string    `json:"thumbnail_url" db:"thumbnail_url"`
+	StartsAt          *time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt            *time.Time `json:"ends_at" db:"ends_at"`
+	StandCount        int        `json:"stand_count"`
+	TombolaCount      int        `json:"tombola_count"`
+	UserCount         int        `json:"user_count"`
+	InteractionCount  int        `json:"interaction_count"`
+	InteractionIncome int        `json:"interaction_income"`
+	TombolaIncome     int        `json:"tombola_income"`
+	Points            int        `json:"points"`
+}
+
+// KermesseStatusChange is a step of the lifecycle of a kermesse, UserId is nil
+// for the changes made by the scheduler.
+type KermesseStatusChange struct {
+	Id         int       `json:"id" db:"id"`
+	KermesseId int       `json:"kermesse_id" db:"kermesse_id"`
+	FromStatus *string   `json:"from_status" db:"from_status"`
+	ToStatus   string    `json:"to_status" db:"to_status"`
+	UserId     *int      `json:"user_id" db:"user_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 }
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+const (
+	CreditTransactionKindOpeningBalance string = "OPENING_BALANCE"
+	CreditTransactionKindTopUp          string = "TOPUP"
+	CreditTransactionKindTransfer       string = "TRANSFER"
+	CreditTransactionKindInteraction    string = "INTERACTION"
+	CreditTransactionKindTicket         string = "TICKET"
+	CreditTransactionKindRefund         string = "REFUND"
+	CreditTransactionKindTopUpRefund    string = "TOPUP_REFUND"
+	CreditTransactionKindTopUpDispute   string = "TOPUP_DISPUTE"
+	CreditTransactionKindCashTopUp      string = "CASH_TOPUP"
+	CreditTransactionKindHold           string = "HOLD"
+	CreditTransactionKindHoldRelease    string = "HOLD_RELEASE"
+)
+
+// CreditTransaction moves Amount credits from the debit account to the credit
+// account. A nil account is the outside of the platform (payment provider,
+// tombola pot...).
+type CreditTransaction struct {
+	Id              int       `json:"id" db:"id"`
+	DebitUserId     *int      `json:"debit_user_id" db:"debit_user_id"`
+	CreditUserId    *int      `json:"credit_user_id" db:"credit_user_id"`
+	Amount          int       `json:"amount" db:"amount"`
+	Kind            string    `json:"kind" db:"kind"`
+	InteractionId   *int      `json:"interaction_id" db:"interaction_id"`
+	TicketId        *int      `json:"ticket_id" db:"ticket_id"`
+	StripeSessionId *string   `json:"stripe_session_id" db:"stripe_session_id"`
+	CashTopUpId     *int      `json:"cash_topup_id" db:"cash_topup_id"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	NotificationKindLowStock   string = "LOW_STOCK"
+	NotificationKindOutOfStock string = "OUT_OF_STOCK"
+
+	NotificationKindKermesseEndBlocked string = "KERMESSE_END_BLOCKED"
+)
+
+// Notification is an in-app message to a user, the same one can also be sent
+// by email.
+type Notification struct {
+	Id         int       `json:"id" db:"id"`
+	UserId     int       `json:"user_id" db:"user_id"`
+	Kind       string    `json:"kind" db:"kind"`
+	Title      string    `json:"title" db:"title"`
+	Message    string    `json:"message" db:"message"`
+	StandId    *int      `json:"stand_id" db:"stand_id"`
+	KermesseId *int      `json:"kermesse_id" db:"kermesse_id"`
+	IsRead     bool      `json:"is_read" db:"is_read"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
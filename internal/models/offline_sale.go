@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	OfflineSaleStatusPending  string = "PENDING"
+	OfflineSaleStatusAccepted string = "ACCEPTED"
+	OfflineSaleStatusRejected string = "REJECTED"
+
+	OfflineSaleResultAccepted        string = "ACCEPTED"
+	OfflineSaleResultNotEnoughCredit string = "REJECTED_NOT_ENOUGH_CREDIT"
+	OfflineSaleResultNotEnoughStock  string = "REJECTED_NOT_ENOUGH_STOCK"
+	OfflineSaleResultRejected        string = "REJECTED"
+	OfflineSaleResultDuplicate       string = "DUPLICATE"
+	OfflineSaleResultInvalid         string = "INVALID"
+)
+
+// OfflineSale is a sale a stand device recorded offline, Reason is the error
+// key of the purchase check that rejected it.
+type OfflineSale struct {
+	Id             int       `json:"id" db:"id"`
+	StandId        int       `json:"stand_id" db:"stand_id"`
+	KermesseId     int       `json:"kermesse_id" db:"kermesse_id"`
+	UserId         int       `json:"user_id" db:"user_id"`
+	SyncedBy       int       `json:"synced_by" db:"synced_by"`
+	IdempotencyKey string    `json:"idempotency_key" db:"idempotency_key"`
+	Status         string    `json:"status" db:"status"`
+	Reason         *string   `json:"reason" db:"reason"`
+	Error          *string   `json:"error" db:"error"`
+	InteractionId  *int      `json:"interaction_id" db:"interaction_id"`
+	IsResolved     bool      `json:"is_resolved" db:"is_resolved"`
+	SoldAt         time.Time `json:"sold_at" db:"sold_at"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// OfflineSaleResult tells a stand device what became of a synced sale.
+type OfflineSaleResult struct {
+	IdempotencyKey string  `json:"idempotency_key"`
+	Result         string  `json:"result"`
+	InteractionId  *int    `json:"interaction_id"`
+	Error          *string `json:"error"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PayToken is a payment token of a child, it can be used once before
+// ExpiresAt.
+type PayToken struct {
+	Id            int        `json:"id" db:"id"`
+	UserId        int        `json:"user_id" db:"user_id"`
+	TokenId       string     `json:"token_id" db:"token_id"`
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt        *time.Time `json:"used_at" db:"used_at"`
+	UsedBy        *int       `json:"used_by" db:"used_by"`
+	InteractionId *int       `json:"interaction_id" db:"interaction_id"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// StandProduct is an item of the catalog of a stand, inactive products can't
+// be bought anymore but stay in the history of interactions.
+type StandProduct struct {
+	Id                int       `json:"id" db:"id"`
+	StandId           int       `json:"stand_id" db:"stand_id"`
+	Name              string    `json:"name" db:"name"`
+	Price             int       `json:"price" db:"price"`
+	Stock             int       `json:"stock" db:"stock"`
+	LowStockThreshold *int      `json:"low_stock_threshold" db:"low_stock_threshold"`
+	StockAlert        *string   `json:"stock_alert" db:"stock_alert"`
+	IsActive          bool      `json:"is_active" db:"is_active"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+}
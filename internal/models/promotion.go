@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	PromotionTypePercentage string = "PERCENTAGE"
+	PromotionTypeFixed      string = "FIXED"
+)
+
+// Promotion discounts the units sold by a stand, or only one of its products
+// when ProductId is set: Value is a percentage of the price for PERCENTAGE
+// and an amount of credits off each unit for FIXED.
+type Promotion struct {
+	Id            int       `json:"id" db:"id"`
+	StandId       int       `json:"stand_id" db:"stand_id"`
+	ProductId     *int      `json:"product_id" db:"product_id"`
+	Name          string    `json:"name" db:"name"`
+	Type          string    `json:"type" db:"type"`
+	Value         int       `json:"value" db:"value"`
+	StartsAt      time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt        time.Time `json:"ends_at" db:"ends_at"`
+	QuantityLimit *int      `json:"quantity_limit" db:"quantity_limit"`
+	UsedQuantity  int       `json:"used_quantity" db:"used_quantity"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
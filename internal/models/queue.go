@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+const (
+	QueueEntryStatusWaiting string = "WAITING"
+	QueueEntryStatusCalled  string = "CALLED"
+	QueueEntryStatusNoShow  string = "NO_SHOW"
+	QueueEntryStatusLeft    string = "LEFT"
+	QueueEntryStatusFailed  string = "FAILED"
+)
+
+// QueueEntry is a child waiting at an activity stand, Position and
+// EstimatedWait (in seconds) are only computed for waiting entries.
+type QueueEntry struct {
+	Id            int        `json:"id" db:"id"`
+	StandId       int        `json:"stand_id" db:"stand_id"`
+	KermesseId    int        `json:"kermesse_id" db:"kermesse_id"`
+	UserId        int        `json:"user_id" db:"user_id"`
+	UserName      string     `json:"user_name" db:"user_name"`
+	CreatedBy     int        `json:"created_by" db:"created_by"`
+	Status        string     `json:"status" db:"status"`
+	InteractionId *int       `json:"interaction_id" db:"interaction_id"`
+	Error         *string    `json:"error" db:"error"`
+	CalledAt      *time.Time `json:"called_at" db:"called_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	Position      int        `json:"position" db:"-"`
+	EstimatedWait *int       `json:"estimated_wait" db:"-"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ScoringRule bounds the points an activity stand awards in a kermesse,
+// ParticipationBonus is added to every awarded score.
+type ScoringRule struct {
+	Id                 int           `json:"id" db:"id"`
+	KermesseId         int           `json:"kermesse_id" db:"kermesse_id"`
+	StandId            int           `json:"stand_id" db:"stand_id"`
+	MinPoints          int           `json:"min_points" db:"min_points"`
+	MaxPoints          int           `json:"max_points" db:"max_points"`
+	ParticipationBonus int           `json:"participation_bonus" db:"participation_bonus"`
+	CreatedAt          time.Time     `json:"created_at" db:"created_at"`
+	Tiers              []ScoringTier `json:"tiers" db:"-"`
+}
+
+// ScoringTier is a fixed award of a rule, e.g. bronze, silver or gold.
+type ScoringTier struct {
+	Id            int    `json:"id" db:"id"`
+	ScoringRuleId int    `json:"scoring_rule_id" db:"scoring_rule_id"`
+	Name          string `json:"name" db:"name"`
+	Points        int    `json:"points" db:"points"`
+}
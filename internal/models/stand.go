@@ -1,16 +1,60 @@
 package models
 
+import "time"
+
 const (
 	StandTypeBuyer    string = "CONSUMPTION"
 	StandTypeActivity string = "ACTIVITY"
+
+	StandMemberRoleOwner   string = "OWNER"
+	StandMemberRoleCashier string = "CASHIER"
 )
 
+// Stand is a stand, IsOpen and NextOpeningAt are only reported when the
+// stands of a kermesse are listed.
 type Stand struct {
-	Id          int    `json:"id" db:"id"`
-	UserId      int    `json:"user_id" db:"user_id"`
-	Name        string `json:"name" db:"name"`
-	Description string `json:"description" db:"description"`
-	Type        string `json:"type" db:"type"`
-	Price       int    `json:"price" db:"price"`
-	Stock       int    `json:"stock" db:"stock"`
+	Id                int        `json:"id" db:"id"`
+	UserId            int        `json:"user_id" db:"user_id"`
+	Name              string     `json:"name" db:"name"`
+	Description       string     `json:"description" db:"description"`
+	Type              string     `json:"type" db:"type"`
+	Price             int        `json:"price" db:"price"`
+	Stock             int        `json:"stock" db:"stock"`
+	LowStockThreshold *int       `json:"low_stock_threshold" db:"low_stock_threshold"`
+	StockAlert        *string    `json:"stock_alert" db:"stock_alert"`
+	IsPaused          bool       `json:"is_paused" db:"is_paused"`
+	ImageURL          *string    `json:"image_url" db:"image_url"`
+	ThumbnailURL      *string    `json:"thumbnail_url" db:"thumbnail_url"`
+	Tags              []Tag      `json:"tags" db:"-"`
+	IsOpen            *bool      `json:"is_open,omitempty" db:"-"`
+	NextOpeningAt     *time.Time `json:"next_opening_at,omitempty" db:"-"`
+}
+
+// StandMember is a stand holder working at a stand, owners manage the stand
+// and its staff while cashiers only serve the customers.
+type StandMember struct {
+	Id        int       `json:"id" db:"id"`
+	StandId   int       `json:"stand_id" db:"stand_id"`
+	UserId    int       `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	Email     string    `json:"email" db:"email"`
+	Role      string    `json:"role" db:"role"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// StandSchedule is an opening slot of a stand in a kermesse.
+type StandSchedule struct {
+	Id         int       `json:"id" db:"id"`
+	StandId    int       `json:"stand_id" db:"stand_id"`
+	KermesseId int       `json:"kermesse_id" db:"kermesse_id"`
+	OpensAt    time.Time `json:"opens_at" db:"opens_at"`
+	ClosesAt   time.Time `json:"closes_at" db:"closes_at"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// StandOpening tells whether the current time is in an opening slot of a
+// stand in a kermesse, a stand without slots is always in one.
+type StandOpening struct {
+	IsScheduled   bool       `db:"is_scheduled"`
+	NextOpeningAt *time.Time `db:"next_opening_at"`
 }
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+const (
+	StockMovementKindInitial    string = "INITIAL"
+	StockMovementKindRestock    string = "RESTOCK"
+	StockMovementKindSale       string = "SALE"
+	StockMovementKindRefund     string = "REFUND"
+	StockMovementKindAdjustment string = "ADJUSTMENT"
+	StockMovementKindWaste      string = "WASTE"
+
+	StockAlertLow string = "LOW_STOCK"
+	StockAlertOut string = "OUT_OF_STOCK"
+)
+
+// StockMovement is a change of the stock of a stand, or of one of its
+// products when ProductId is set. Quantity is signed, UserId is the author
+// and is nil for the changes made by the scheduler.
+type StockMovement struct {
+	Id            int       `json:"id" db:"id"`
+	StandId       int       `json:"stand_id" db:"stand_id"`
+	ProductId     *int      `json:"product_id" db:"product_id"`
+	KermesseId    *int      `json:"kermesse_id" db:"kermesse_id"`
+	Kind          string    `json:"kind" db:"kind"`
+	Quantity      int       `json:"quantity" db:"quantity"`
+	StockAfter    int       `json:"stock_after" db:"stock_after"`
+	Reason        string    `json:"reason" db:"reason"`
+	UserId        *int      `json:"user_id" db:"user_id"`
+	InteractionId *int      `json:"interaction_id" db:"interaction_id"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// StockReportLine sums the movements of the stand, or of one of its
+// products, during a kermesse. Sold, Wasted and Refunded are counted in
+// units, Adjusted is signed.
+type StockReportLine struct {
+	ProductId    *int   `json:"product_id" db:"product_id"`
+	Name         string `json:"name" db:"name"`
+	OpeningStock int    `json:"opening_stock" db:"opening_stock"`
+	Restocked    int    `json:"restocked" db:"restocked"`
+	Sold         int    `json:"sold" db:"sold"`
+	Refunded     int    `json:"refunded" db:"refunded"`
+	Wasted       int    `json:"wasted" db:"wasted"`
+	Adjusted     int    `json:"adjusted" db:"adjusted"`
+	ClosingStock int    `json:"closing_stock" db:"closing_stock"`
+}
+
+// StockAlert is raised by the movement taking the stock of a stand, or of one
+// of its products, down to its low-stock threshold or out of stock.
+type StockAlert struct {
+	Kind       string
+	StandId    int
+	ProductId  *int
+	KermesseId *int
+	Stock      int
+}
@@ -0,0 +1,21 @@
+package models
+
+const (
+	TagTypeAllergen string = "ALLERGEN"
+	TagTypeDietary  string = "DIETARY"
+	TagTypeCategory string = "CATEGORY"
+)
+
+// Tag describes what a stand serves, allergen tags are also the allergies a
+// child can declare.
+type Tag struct {
+	Id   int    `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+	Type string `json:"type" db:"type"`
+}
+
+// StandTag is a tag of a stand, as listed for several stands at once.
+type StandTag struct {
+	StandId int `db:"stand_id"`
+	Tag
+}
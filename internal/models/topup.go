@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+const (
+	TopUpStatusPending           string = "PENDING"
+	TopUpStatusProcessing        string = "PROCESSING"
+	TopUpStatusCompleted         string = "COMPLETED"
+	TopUpStatusExpired           string = "EXPIRED"
+	TopUpStatusFailed            string = "FAILED"
+	TopUpStatusPartiallyRefunded string = "PARTIALLY_REFUNDED"
+	TopUpStatusRefunded          string = "REFUNDED"
+	TopUpStatusDisputed          string = "DISPUTED"
+)
+
+type TopUp struct {
+	Id              int       `json:"id" db:"id"`
+	UserId          int       `json:"user_id" db:"user_id"`
+	SessionId       string    `json:"session_id" db:"session_id"`
+	Amount          int       `json:"amount" db:"amount"`
+	Credit          int       `json:"credit" db:"credit"`
+	Status          string    `json:"status" db:"status"`
+	PaymentIntentId *string   `json:"payment_intent_id" db:"payment_intent_id"`
+	RefundedCredit  int       `json:"refunded_credit" db:"refunded_credit"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type TopUpSession struct {
+	SessionId string `json:"session_id"`
+	URL       string `json:"url,omitempty"`
+	Amount    int    `json:"amount"`
+	Credit    int    `json:"credit"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+const (
+	WebhookEventStatusReceived  string = "RECEIVED"
+	WebhookEventStatusProcessed string = "PROCESSED"
+	WebhookEventStatusIgnored   string = "IGNORED"
+	WebhookEventStatusFailed    string = "FAILED"
+)
+
+type WebhookEvent struct {
+	Id          int        `json:"id" db:"id"`
+	EventId     string     `json:"event_id" db:"event_id"`
+	Type        string     `json:"type" db:"type"`
+	SessionId   *string    `json:"session_id" db:"session_id"`
+	Payload     []byte     `json:"payload" db:"payload"`
+	Status      string     `json:"status" db:"status"`
+	Error       *string    `json:"error" db:"error"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	ProcessedAt *time.Time `json:"processed_at" db:"processed_at"`
+}
@@ -0,0 +1,87 @@
+package notification
+
+import (
+	goErrors "errors"
+
+	"standmaster/internal/models"
+	"standmaster/internal/user"
+	"standmaster/third_party/resend"
+)
+
+// Notifier delivers a notification to each of the users.
+type Notifier interface {
+	Notify(userIds []int, notification models.Notification) error
+}
+
+// InApp stores the notifications so the users find them in the app.
+type InApp struct {
+	repository NotificationRepository
+}
+
+func NewInAppNotifier(repository NotificationRepository) *InApp {
+	return &InApp{
+		repository: repository,
+	}
+}
+
+func (n *InApp) Notify(userIds []int, notification models.Notification) error {
+	for _, userId := range userIds {
+		err := n.repository.Create(map[string]interface{}{
+			"user_id":     userId,
+			"kind":        notification.Kind,
+			"title":       notification.Title,
+			"message":     notification.Message,
+			"stand_id":    notification.StandId,
+			"kermesse_id": notification.KermesseId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Email sends the notifications to the email address of the users.
+type Email struct {
+	resendService  resend.ResendService
+	userRepository user.UserRepository
+}
+
+func NewEmailNotifier(resendService resend.ResendService, userRepository user.UserRepository) *Email {
+	return &Email{
+		resendService:  resendService,
+		userRepository: userRepository,
+	}
+}
+
+func (n *Email) Notify(userIds []int, notification models.Notification) error {
+	for _, userId := range userIds {
+		user, err := n.userRepository.FindById(userId)
+		if err != nil {
+			return err
+		}
+
+		_, err = n.resendService.SendNotificationEmail(user.Email, notification.Title, notification.Message)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Notifiers delivers the notifications through all of its notifiers, one
+// failing doesn't keep the others from delivering them.
+type Notifiers []Notifier
+
+func (n Notifiers) Notify(userIds []int, notification models.Notification) error {
+	var errs []error
+	for _, notifier := range n {
+		if err := notifier.Notify(userIds, notification); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return goErrors.Join(errs...)
+}
@@ -0,0 +1,74 @@
+package notification
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+	"standmaster/third_party/database"
+)
+
+type NotificationRepository interface {
+	WithTx(tx *sqlx.Tx) NotificationRepository
+	FindAllByUserId(userId int, filters map[string]interface{}) ([]models.Notification, error)
+	Create(input map[string]interface{}) error
+	Read(id int, userId int) error
+}
+
+type Repository struct {
+	db database.Queryer
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) WithTx(tx *sqlx.Tx) NotificationRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
+func (s *Repository) FindAllByUserId(userId int, filters map[string]interface{}) ([]models.Notification, error) {
+	notifications := []models.Notification{}
+	query := "SELECT * FROM notifications WHERE user_id=$1"
+	args := []interface{}{userId}
+	if filters["is_read"] != nil {
+		args = append(args, filters["is_read"])
+		query += fmt.Sprintf(" AND is_read=$%d", len(args))
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+	err := s.db.Select(&notifications, query, args...)
+
+	return notifications, err
+}
+
+func (s *Repository) Create(input map[string]interface{}) error {
+	query := "INSERT INTO notifications (user_id, kind, title, message, stand_id, kermesse_id) VALUES ($1, $2, $3, $4, $5, $6)"
+	_, err := s.db.Exec(query, input["user_id"], input["kind"], input["title"], input["message"], input["stand_id"], input["kermesse_id"])
+
+	return err
+}
+
+// Read marks the notification of the user as read, sql.ErrNoRows is returned
+// when the user has no such notification.
+func (s *Repository) Read(id int, userId int) error {
+	query := "UPDATE notifications SET is_read=true WHERE id=$1 AND user_id=$2"
+	result, err := s.db.Exec(query, id, userId)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
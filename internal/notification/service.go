@@ -0,0 +1,85 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+	"strconv"
+
+	"standmaster/internal/models"
+	"standmaster/pkg/errors"
+)
+
+type NotificationService interface {
+	GetAll(ctx context.Context, params map[string]interface{}) ([]models.Notification, error)
+	Read(ctx context.Context, id int) error
+}
+
+type Service struct {
+	repository NotificationRepository
+}
+
+func NewService(repository NotificationRepository) *Service {
+	return &Service{
+		repository: repository,
+	}
+}
+
+// GetAll lists the notifications of the user, the latest first.
+func (s *Service) GetAll(ctx context.Context, params map[string]interface{}) ([]models.Notification, error) {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return nil, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	filters := map[string]interface{}{}
+	if params["is_read"] != nil {
+		value, _ := params["is_read"].(string)
+		isRead, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		filters["is_read"] = isRead
+	}
+
+	notifications, err := s.repository.FindAllByUserId(userId, filters)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return notifications, nil
+}
+
+func (s *Service) Read(ctx context.Context, id int) error {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	if err := s.repository.Read(id, userId); err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
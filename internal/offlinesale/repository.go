@@ -0,0 +1,90 @@
+package offlinesale
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+	"standmaster/third_party/database"
+)
+
+type OfflineSaleRepository interface {
+	WithTx(tx *sqlx.Tx) OfflineSaleRepository
+	FindAllByKermesseId(kermesseId int, filters map[string]interface{}) ([]models.OfflineSale, error)
+	FindById(id int) (models.OfflineSale, error)
+	FindByKey(standId int, key string) (models.OfflineSale, error)
+	Create(input map[string]interface{}) (int, error)
+	Resolve(id int) error
+}
+
+type Repository struct {
+	db database.Queryer
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) WithTx(tx *sqlx.Tx) OfflineSaleRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
+func (s *Repository) FindAllByKermesseId(kermesseId int, filters map[string]interface{}) ([]models.OfflineSale, error) {
+	sales := []models.OfflineSale{}
+	query := "SELECT * FROM offline_sales WHERE kermesse_id=$1"
+	args := []interface{}{kermesseId}
+	if filters["status"] != nil {
+		args = append(args, filters["status"])
+		query += fmt.Sprintf(" AND status=$%d", len(args))
+	}
+	if filters["is_resolved"] != nil {
+		args = append(args, filters["is_resolved"])
+		query += fmt.Sprintf(" AND is_resolved=$%d", len(args))
+	}
+	query += " ORDER BY sold_at DESC, id DESC"
+	err := s.db.Select(&sales, query, args...)
+
+	return sales, err
+}
+
+func (s *Repository) FindById(id int) (models.OfflineSale, error) {
+	sale := models.OfflineSale{}
+	query := "SELECT * FROM offline_sales WHERE id=$1"
+	err := s.db.Get(&sale, query, id)
+
+	return sale, err
+}
+
+func (s *Repository) FindByKey(standId int, key string) (models.OfflineSale, error) {
+	sale := models.OfflineSale{}
+	query := "SELECT * FROM offline_sales WHERE stand_id=$1 AND idempotency_key=$2"
+	err := s.db.Get(&sale, query, standId, key)
+
+	return sale, err
+}
+
+// Create records the result of a replayed sale, it returns sql.ErrNoRows
+// when the stand already synced a sale with the same key.
+func (s *Repository) Create(input map[string]interface{}) (int, error) {
+	var id int
+	query := `
+		INSERT INTO offline_sales (stand_id, kermesse_id, user_id, synced_by, idempotency_key, status, reason, error, interaction_id, sold_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (stand_id, idempotency_key) DO NOTHING
+		RETURNING id
+	`
+	err := s.db.QueryRow(query, input["stand_id"], input["kermesse_id"], input["user_id"], input["synced_by"], input["idempotency_key"], input["status"], input["reason"], input["error"], input["interaction_id"], input["sold_at"]).Scan(&id)
+
+	return id, err
+}
+
+func (s *Repository) Resolve(id int) error {
+	query := "UPDATE offline_sales SET is_resolved=TRUE WHERE id=$1"
+	_, err := s.db.Exec(query, id)
+
+	return err
+}
@@ -0,0 +1,387 @@
+package offlinesale
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/interaction"
+	"standmaster/internal/kermesse"
+	"standmaster/internal/models"
+	"standmaster/internal/stand"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/utils"
+)
+
+// maxBatchSize is the number of sales a device can sync at once.
+const maxBatchSize = 500
+
+// clockSkew is how far in the future the clock of a device can be.
+const clockSkew = 5 * time.Minute
+
+type OfflineSaleService interface {
+	Sync(ctx context.Context, standId int, input map[string]interface{}) ([]models.OfflineSaleResult, error)
+	GetAll(ctx context.Context, kermesseId int, params map[string]interface{}) ([]models.OfflineSale, error)
+	Resolve(ctx context.Context, kermesseId int, id int) error
+}
+
+type Service struct {
+	repository         OfflineSaleRepository
+	standRepository    stand.StandRepository
+	kermesseRepository kermesse.KermesseRepository
+	userRepository     user.UserRepository
+	interactionService interaction.InteractionService
+}
+
+func NewService(repository OfflineSaleRepository, standRepository stand.StandRepository, kermesseRepository kermesse.KermesseRepository, userRepository user.UserRepository, interactionService interaction.InteractionService) *Service {
+	return &Service{
+		repository:         repository,
+		standRepository:    standRepository,
+		kermesseRepository: kermesseRepository,
+		userRepository:     userRepository,
+		interactionService: interactionService,
+	}
+}
+
+// Sync replays the sales a member of the stand recorded offline in the
+// kermesse through the purchase checks, in the given order, and returns the
+// result of each of them. A sale already synced is reported as a duplicate
+// and not replayed.
+func (s *Service) Sync(ctx context.Context, standId int, input map[string]interface{}) ([]models.OfflineSaleResult, error) {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return nil, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+	if err := s.checkMember(userId, standId); err != nil {
+		return nil, err
+	}
+
+	kermesseId, err := utils.GetIntFromMap(input, "kermesse_id")
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+	hasStand, err := s.kermesseRepository.HasStand(kermesseId, standId)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if !hasStand {
+		return nil, errors.CustomError{
+			Key: errors.NotFound,
+			Err: goErrors.New("stand is not in the kermesse"),
+		}
+	}
+
+	sales, ok := input["sales"].([]interface{})
+	if !ok {
+		return nil, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("sales is not a valid list"),
+		}
+	}
+	if len(sales) > maxBatchSize {
+		return nil, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("too many sales, sync at most " + strconv.Itoa(maxBatchSize)),
+		}
+	}
+
+	results := []models.OfflineSaleResult{}
+	for _, value := range sales {
+		sale, _ := value.(map[string]interface{})
+		result, err := s.replay(ctx, userId, standId, kermesseId, sale)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// replay charges the buyer of an offline sale. The accepted sale is recorded
+// in the transaction of the purchase so it can't be charged without being
+// recorded. Only unexpected errors are returned, nothing is then recorded so
+// the device can sync the sale again.
+func (s *Service) replay(ctx context.Context, userId int, standId int, kermesseId int, sale map[string]interface{}) (models.OfflineSaleResult, error) {
+	key, _ := sale["idempotency_key"].(string)
+	result := models.OfflineSaleResult{
+		IdempotencyKey: strings.TrimSpace(key),
+	}
+
+	buyerId, soldAt, err := parse(sale)
+	if err == nil {
+		_, err = s.userRepository.FindById(buyerId)
+		if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+			return result, errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		if err != nil {
+			err = goErrors.New("user not found")
+		}
+	}
+	if err != nil {
+		message := err.Error()
+		result.Result = models.OfflineSaleResultInvalid
+		result.Error = &message
+		return result, nil
+	}
+
+	_, err = s.repository.FindByKey(standId, result.IdempotencyKey)
+	if err == nil {
+		return s.duplicate(standId, result)
+	}
+	if !goErrors.Is(err, sql.ErrNoRows) {
+		return result, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	record := map[string]interface{}{
+		"stand_id":        standId,
+		"kermesse_id":     kermesseId,
+		"user_id":         buyerId,
+		"synced_by":       userId,
+		"idempotency_key": result.IdempotencyKey,
+		"sold_at":         soldAt,
+	}
+	purchase := map[string]interface{}{
+		"stand_id":    standId,
+		"kermesse_id": kermesseId,
+		"sold_at":     soldAt,
+		"quantity":    sale["quantity"],
+		"items":       sale["items"],
+	}
+	// a sale synced meanwhile by another request rolls the purchase back
+	isDuplicate := false
+	charged, chargeErr := s.interactionService.CreateForUserWith(ctx, buyerId, purchase, func(tx *sqlx.Tx, interactionId int) error {
+		record["status"] = models.OfflineSaleStatusAccepted
+		record["interaction_id"] = interactionId
+		_, err := s.repository.WithTx(tx).Create(record)
+		isDuplicate = goErrors.Is(err, sql.ErrNoRows)
+		return err
+	})
+	if isDuplicate {
+		return s.duplicate(standId, result)
+	}
+	if chargeErr != nil {
+		reason := errors.FromError(chargeErr).Key
+		if reason == errors.InternalServerError {
+			return result, chargeErr
+		}
+
+		message := chargeErr.Error()
+		record["status"] = models.OfflineSaleStatusRejected
+		record["reason"] = reason
+		record["error"] = message
+		record["interaction_id"] = nil
+		_, err = s.repository.Create(record)
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return s.duplicate(standId, result)
+		}
+		if err != nil {
+			return result, errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		switch reason {
+		case errors.NotEnoughCredit:
+			result.Result = models.OfflineSaleResultNotEnoughCredit
+		case errors.NotEnoughStock:
+			result.Result = models.OfflineSaleResultNotEnoughStock
+		default:
+			result.Result = models.OfflineSaleResultRejected
+		}
+		result.Error = &message
+		return result, nil
+	}
+
+	result.Result = models.OfflineSaleResultAccepted
+	result.InteractionId = &charged.Id
+	return result, nil
+}
+
+// duplicate reports a sale the stand already synced with what became of it.
+func (s *Service) duplicate(standId int, result models.OfflineSaleResult) (models.OfflineSaleResult, error) {
+	synced, err := s.repository.FindByKey(standId, result.IdempotencyKey)
+	if err != nil {
+		return result, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	result.Result = models.OfflineSaleResultDuplicate
+	result.InteractionId = synced.InteractionId
+	result.Error = synced.Error
+	return result, nil
+}
+
+// GetAll lets the organizer review the offline sales of the kermesse, e.g.
+// the rejected ones not resolved yet with status=REJECTED&is_resolved=false.
+func (s *Service) GetAll(ctx context.Context, kermesseId int, params map[string]interface{}) ([]models.OfflineSale, error) {
+	if err := s.checkOrganizer(ctx, kermesseId); err != nil {
+		return nil, err
+	}
+
+	filters := map[string]interface{}{}
+	if params["status"] != nil {
+		status, _ := params["status"].(string)
+		if status != models.OfflineSaleStatusPending && status != models.OfflineSaleStatusAccepted && status != models.OfflineSaleStatusRejected {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("status is not valid"),
+			}
+		}
+		filters["status"] = status
+	}
+	if params["is_resolved"] != nil {
+		value, _ := params["is_resolved"].(string)
+		isResolved, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		filters["is_resolved"] = isResolved
+	}
+
+	sales, err := s.repository.FindAllByKermesseId(kermesseId, filters)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return sales, nil
+}
+
+// Resolve lets the organizer mark a rejected sale as settled with the stand.
+func (s *Service) Resolve(ctx context.Context, kermesseId int, id int) error {
+	if err := s.checkOrganizer(ctx, kermesseId); err != nil {
+		return err
+	}
+
+	sale, err := s.repository.FindById(id)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if sale.KermesseId != kermesseId {
+		return errors.CustomError{
+			Key: errors.NotFound,
+			Err: goErrors.New("sale not found"),
+		}
+	}
+	if sale.Status != models.OfflineSaleStatusRejected {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("sale was not rejected"),
+		}
+	}
+
+	if err := s.repository.Resolve(id); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// parse reads the buyer and the time of an offline sale.
+func parse(sale map[string]interface{}) (int, time.Time, error) {
+	key, _ := sale["idempotency_key"].(string)
+	if strings.TrimSpace(key) == "" {
+		return 0, time.Time{}, goErrors.New("idempotency_key is missing")
+	}
+
+	value, _ := sale["sold_at"].(string)
+	soldAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, time.Time{}, goErrors.New("sold_at is not a valid date")
+	}
+	if soldAt.After(time.Now().Add(clockSkew)) {
+		return 0, time.Time{}, goErrors.New("sold_at is in the future")
+	}
+
+	buyerId, err := utils.GetIntFromMap(sale, "user_id")
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	return buyerId, soldAt, nil
+}
+
+func (s *Service) checkMember(userId int, standId int) error {
+	member, err := s.standRepository.FindMemberByUserId(userId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if member.StandId != standId {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not a member of the stand"),
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) checkOrganizer(ctx context.Context, kermesseId int) error {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	kermesse, err := s.kermesseRepository.FindById(kermesseId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if kermesse.UserId != userId {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not the organizer of the kermesse"),
+		}
+	}
+
+	return nil
+}
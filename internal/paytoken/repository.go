@@ -0,0 +1,61 @@
+package paytoken
+
+import (
+	"github.com/jmoiron/sqlx"
+	"standmaster/third_party/database"
+)
+
+type PayTokenRepository interface {
+	WithTx(tx *sqlx.Tx) PayTokenRepository
+	Create(input map[string]interface{}) error
+	ExpireAllByUserId(userId int) error
+	Use(input map[string]interface{}) error
+}
+
+type Repository struct {
+	db database.Queryer
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) WithTx(tx *sqlx.Tx) PayTokenRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
+func (s *Repository) Create(input map[string]interface{}) error {
+	query := "INSERT INTO pay_tokens (user_id, token_id, expires_at) VALUES ($1, $2, $3)"
+	_, err := s.db.Exec(query, input["user_id"], input["token_id"], input["expires_at"])
+
+	return err
+}
+
+// ExpireAllByUserId ends the unused tokens of the user, only the last token
+// shown by a child can be used.
+func (s *Repository) ExpireAllByUserId(userId int) error {
+	query := "UPDATE pay_tokens SET expires_at=CURRENT_TIMESTAMP WHERE user_id=$1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP"
+	_, err := s.db.Exec(query, userId)
+
+	return err
+}
+
+// Use marks the token of the child as used by a member of the stand for the
+// interaction, it returns sql.ErrNoRows when the token is unknown, expired or
+// already used.
+func (s *Repository) Use(input map[string]interface{}) error {
+	var id int
+	query := `
+		UPDATE pay_tokens
+		SET used_at=CURRENT_TIMESTAMP, used_by=$1, interaction_id=$2
+		WHERE token_id=$3 AND user_id=$4 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		RETURNING id
+	`
+	err := s.db.QueryRow(query, input["used_by"], input["interaction_id"], input["token_id"], input["user_id"]).Scan(&id)
+
+	return err
+}
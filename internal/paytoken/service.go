@@ -0,0 +1,209 @@
+package paytoken
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	goErrors "errors"
+	"os"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/interaction"
+	"standmaster/internal/models"
+	"standmaster/internal/stand"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/jwt"
+	"standmaster/pkg/utils"
+	"standmaster/third_party/database"
+)
+
+type PayTokenService interface {
+	Create(ctx context.Context) (string, error)
+	Charge(ctx context.Context, input map[string]interface{}) (models.Interaction, error)
+}
+
+type Service struct {
+	repository         PayTokenRepository
+	standRepository    stand.StandRepository
+	interactionService interaction.InteractionService
+	transactor         database.Transactor
+}
+
+func NewService(repository PayTokenRepository, standRepository stand.StandRepository, interactionService interaction.InteractionService, transactor database.Transactor) *Service {
+	return &Service{
+		repository:         repository,
+		standRepository:    standRepository,
+		interactionService: interactionService,
+		transactor:         transactor,
+	}
+}
+
+// Create signs a new payment token for the child, the previous ones can't be
+// used anymore.
+func (s *Service) Create(ctx context.Context) (string, error) {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return "", errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	ttl, err := tokenTTL()
+	if err != nil {
+		return "", errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	tokenId, err := randomId()
+	if err != nil {
+		return "", errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	err = s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+
+		if err := repository.ExpireAllByUserId(userId); err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		err := repository.Create(map[string]interface{}{
+			"user_id":    userId,
+			"token_id":   tokenId,
+			"expires_at": expiresAt,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", errors.FromError(err)
+	}
+
+	token, err := jwt.CreatePayToken(os.Getenv("JWT_SECRET"), expiresAt, userId, tokenId)
+	if err != nil {
+		return "", errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return token, nil
+}
+
+// Charge lets a member of the stand charge the child who showed the token
+// given in input, the rest of input is the purchase. When the purchase is
+// refused the token stays unused so the child can show it again.
+func (s *Service) Charge(ctx context.Context, input map[string]interface{}) (models.Interaction, error) {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return models.Interaction{}, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	standId, err := utils.GetIntFromMap(input, "stand_id")
+	if err != nil {
+		return models.Interaction{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+	if err := s.checkMember(userId, standId); err != nil {
+		return models.Interaction{}, err
+	}
+
+	value, _ := input["token"].(string)
+	childId, tokenId, err := jwt.GetPayToken(value, os.Getenv("JWT_SECRET"))
+	if err != nil {
+		return models.Interaction{}, errors.CustomError{
+			Key: errors.InvalidPayToken,
+			Err: goErrors.New("invalid payment token"),
+		}
+	}
+
+	purchase := map[string]interface{}{}
+	for key, value := range input {
+		if key != "token" {
+			purchase[key] = value
+		}
+	}
+
+	// the token is used in the transaction of the purchase, a token used
+	// meanwhile by another charge rolls the purchase back
+	return s.interactionService.CreateForUserWith(ctx, childId, purchase, func(tx *sqlx.Tx, interactionId int) error {
+		err := s.repository.WithTx(tx).Use(map[string]interface{}{
+			"token_id":       tokenId,
+			"user_id":        childId,
+			"used_by":        userId,
+			"interaction_id": interactionId,
+		})
+		if err != nil {
+			if goErrors.Is(err, sql.ErrNoRows) {
+				return errors.CustomError{
+					Key: errors.InvalidPayToken,
+					Err: goErrors.New("payment token is expired or already used"),
+				}
+			}
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *Service) checkMember(userId int, standId int) error {
+	member, err := s.standRepository.FindMemberByUserId(userId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if member.StandId != standId {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not a member of the stand"),
+		}
+	}
+
+	return nil
+}
+
+// tokenTTL is how long a child has to show a token, one minute by default.
+func tokenTTL() (time.Duration, error) {
+	value := os.Getenv("PAY_TOKEN_TTL")
+	if value == "" {
+		return time.Minute, nil
+	}
+
+	return time.ParseDuration(value)
+}
+
+func randomId() (string, error) {
+	buffer := make([]byte, 16)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buffer), nil
+}
@@ -0,0 +1,75 @@
+package product
+
+import (
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+	"standmaster/third_party/database"
+)
+
+type ProductRepository interface {
+	WithTx(tx *sqlx.Tx) ProductRepository
+	FindAllByStandId(standId int, filters map[string]interface{}) ([]models.StandProduct, error)
+	FindById(id int) (models.StandProduct, error)
+	FindByIdForUpdate(id int) (models.StandProduct, error)
+	Create(input map[string]interface{}) (int, error)
+	Update(id int, input map[string]interface{}) error
+}
+
+type Repository struct {
+	db database.Queryer
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) WithTx(tx *sqlx.Tx) ProductRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
+func (s *Repository) FindAllByStandId(standId int, filters map[string]interface{}) ([]models.StandProduct, error) {
+	products := []models.StandProduct{}
+	query := "SELECT * FROM stand_products WHERE stand_id=$1"
+	if filters["is_active"] != nil {
+		query += " AND is_active"
+	}
+	query += " ORDER BY name, id"
+	err := s.db.Select(&products, query, standId)
+
+	return products, err
+}
+
+func (s *Repository) FindById(id int) (models.StandProduct, error) {
+	product := models.StandProduct{}
+	query := "SELECT * FROM stand_products WHERE id=$1"
+	err := s.db.Get(&product, query, id)
+
+	return product, err
+}
+
+func (s *Repository) FindByIdForUpdate(id int) (models.StandProduct, error) {
+	product := models.StandProduct{}
+	query := "SELECT * FROM stand_products WHERE id=$1 FOR UPDATE"
+	err := s.db.Get(&product, query, id)
+
+	return product, err
+}
+
+func (s *Repository) Create(input map[string]interface{}) (int, error) {
+	var id int
+	query := "INSERT INTO stand_products (stand_id, name, price) VALUES ($1, $2, $3) RETURNING id"
+	err := s.db.QueryRow(query, input["stand_id"], input["name"], input["price"]).Scan(&id)
+
+	return id, err
+}
+
+func (s *Repository) Update(id int, input map[string]interface{}) error {
+	query := "UPDATE stand_products SET name=$1, price=$2, is_active=$3 WHERE id=$4"
+	_, err := s.db.Exec(query, input["name"], input["price"], input["is_active"], id)
+
+	return err
+}
@@ -0,0 +1,311 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+	"standmaster/internal/stand"
+	"standmaster/internal/stock"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/utils"
+	"standmaster/third_party/database"
+)
+
+type ProductService interface {
+	GetAll(ctx context.Context, standId int) ([]models.StandProduct, error)
+	Create(ctx context.Context, standId int, input map[string]interface{}) error
+	Update(ctx context.Context, standId int, id int, input map[string]interface{}) error
+}
+
+type Service struct {
+	repository      ProductRepository
+	standRepository stand.StandRepository
+	stockRepository stock.StockRepository
+	transactor      database.Transactor
+}
+
+func NewService(repository ProductRepository, standRepository stand.StandRepository, stockRepository stock.StockRepository, transactor database.Transactor) *Service {
+	return &Service{
+		repository:      repository,
+		standRepository: standRepository,
+		stockRepository: stockRepository,
+		transactor:      transactor,
+	}
+}
+
+// GetAll returns the catalog of the stand, inactive products are only listed
+// to the members of the stand.
+func (s *Service) GetAll(ctx context.Context, standId int) ([]models.StandProduct, error) {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return nil, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	stand, err := s.standRepository.FindById(standId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	member, err := s.standRepository.FindMemberByUserId(userId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	filters := map[string]interface{}{}
+	if member.StandId != stand.Id {
+		filters["is_active"] = true
+	}
+
+	products, err := s.repository.FindAllByStandId(standId, filters)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return products, nil
+}
+
+func (s *Service) Create(ctx context.Context, standId int, input map[string]interface{}) error {
+	if err := s.checkOwner(ctx, standId); err != nil {
+		return err
+	}
+
+	userId := ctx.Value(models.UserIDKey).(int)
+
+	product := models.StandProduct{}
+	if err := parse(input, &product); err != nil {
+		return err
+	}
+
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		id, err := s.repository.WithTx(tx).Create(map[string]interface{}{
+			"stand_id": standId,
+			"name":     product.Name,
+			"price":    product.Price,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		if product.Stock == 0 {
+			return nil
+		}
+		_, err = s.stockRepository.WithTx(tx).Create(map[string]interface{}{
+			"stand_id":   standId,
+			"product_id": id,
+			"kind":       models.StockMovementKindInitial,
+			"quantity":   product.Stock,
+			"reason":     "product created",
+			"user_id":    userId,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+// Update changes the given fields of a product, is_active hides it from
+// the catalog. A new stock is logged as a manual adjustment.
+func (s *Service) Update(ctx context.Context, standId int, id int, input map[string]interface{}) error {
+	if err := s.checkOwner(ctx, standId); err != nil {
+		return err
+	}
+	userId := ctx.Value(models.UserIDKey).(int)
+
+	product, err := s.repository.FindById(id)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if product.StandId != standId {
+		return errors.CustomError{
+			Key: errors.NotFound,
+			Err: goErrors.New("product not found"),
+		}
+	}
+
+	if err := parse(input, &product); err != nil {
+		return err
+	}
+	if input["is_active"] != nil {
+		isActive, ok := input["is_active"].(bool)
+		if !ok {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("is_active is not a valid boolean"),
+			}
+		}
+		product.IsActive = isActive
+	}
+
+	err = s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+
+		current, err := repository.FindByIdForUpdate(id)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		err = repository.Update(id, map[string]interface{}{
+			"name":      product.Name,
+			"price":     product.Price,
+			"is_active": product.IsActive,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		if input["stock"] == nil || product.Stock == current.Stock {
+			return nil
+		}
+		_, err = s.stockRepository.WithTx(tx).Create(map[string]interface{}{
+			"stand_id":   standId,
+			"product_id": id,
+			"kind":       models.StockMovementKindAdjustment,
+			"quantity":   product.Stock - current.Stock,
+			"reason":     "stock set on the product",
+			"user_id":    userId,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+// checkOwner makes sure the user is an owner of the stand, cashiers can't
+// change the catalog.
+func (s *Service) checkOwner(ctx context.Context, standId int) error {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	stand, err := s.standRepository.FindById(standId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	member, err := s.standRepository.FindMemberByUserId(userId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if member.StandId != stand.Id || member.Role != models.StandMemberRoleOwner {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not an owner of the stand"),
+		}
+	}
+
+	return nil
+}
+
+// parse sets the name, price and stock given in input on product, a new
+// product needs a name.
+func parse(input map[string]interface{}, product *models.StandProduct) error {
+	if input["name"] != nil || product.Id == 0 {
+		name, _ := input["name"].(string)
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("name is missing"),
+			}
+		}
+		product.Name = name
+	}
+
+	for key, value := range map[string]*int{"price": &product.Price, "stock": &product.Stock} {
+		if input[key] == nil {
+			continue
+		}
+		n, err := utils.GetIntFromMap(input, key)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		if n < 0 {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New(key + " can't be negative"),
+			}
+		}
+		*value = n
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package promotion
+
+import (
+	"time"
+
+	"standmaster/internal/models"
+	"standmaster/pkg/errors"
+)
+
+// Line is a part of a purchase sold at the same unit price, ProductId is nil
+// when the stand itself is bought.
+type Line struct {
+	ProductId *int
+	Price     int
+	Quantity  int
+}
+
+// Quote is the price of a purchase once the best promotion is applied,
+// Promotion is nil when none gives a discount. Quantity is the number of
+// discounted units.
+type Quote struct {
+	Total     int
+	Discount  int
+	Quantity  int
+	Promotion *models.Promotion
+}
+
+// Price applies to the lines the promotion of the stand valid at the time of
+// the purchase giving the largest discount and counts the units it
+// discounted. It must be called in the transaction of the purchase as the
+// promotions stay locked until it ends.
+func Price(repository PromotionRepository, standId int, lines []Line, at time.Time) (Quote, error) {
+	quote := Quote{}
+	for _, line := range lines {
+		quote.Total += line.Price * line.Quantity
+	}
+
+	promotions, err := repository.FindAllValidForUpdate(standId, at)
+	if err != nil {
+		return quote, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	for i := range promotions {
+		discount, quantity := apply(promotions[i], lines)
+		if discount > quote.Discount {
+			quote.Discount = discount
+			quote.Quantity = quantity
+			quote.Promotion = &promotions[i]
+		}
+	}
+	if quote.Promotion == nil {
+		return quote, nil
+	}
+
+	if err := repository.Use(quote.Promotion.Id, quote.Quantity); err != nil {
+		return quote, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	quote.Total -= quote.Discount
+
+	return quote, nil
+}
+
+// apply returns the discount the promotion gives on the lines and the number
+// of units it discounts, up to what is left of its quantity limit.
+func apply(promotion models.Promotion, lines []Line) (int, int) {
+	left := -1
+	if promotion.QuantityLimit != nil {
+		left = *promotion.QuantityLimit - promotion.UsedQuantity
+	}
+
+	discount, quantity := 0, 0
+	for _, line := range lines {
+		if promotion.ProductId != nil && (line.ProductId == nil || *line.ProductId != *promotion.ProductId) {
+			continue
+		}
+
+		units := line.Quantity
+		if left >= 0 && units > left-quantity {
+			units = left - quantity
+		}
+		if units <= 0 {
+			break
+		}
+
+		off := promotion.Value
+		if promotion.Type == models.PromotionTypePercentage {
+			off = line.Price * promotion.Value / 100
+		}
+		if off > line.Price {
+			off = line.Price
+		}
+
+		discount += off * units
+		quantity += units
+	}
+
+	return discount, quantity
+}
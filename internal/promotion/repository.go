@@ -0,0 +1,114 @@
+package promotion
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+	"standmaster/third_party/database"
+)
+
+type PromotionRepository interface {
+	WithTx(tx *sqlx.Tx) PromotionRepository
+	FindAllByStandId(standId int, filters map[string]interface{}) ([]models.Promotion, error)
+	FindAllValidForUpdate(standId int, at time.Time) ([]models.Promotion, error)
+	FindById(id int) (models.Promotion, error)
+	Create(input map[string]interface{}) error
+	Update(id int, input map[string]interface{}) error
+	Delete(id int) error
+	Use(id int, quantity int) error
+	Release(id int, quantity int) error
+}
+
+type Repository struct {
+	db database.Queryer
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) WithTx(tx *sqlx.Tx) PromotionRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
+func (s *Repository) FindAllByStandId(standId int, filters map[string]interface{}) ([]models.Promotion, error) {
+	promotions := []models.Promotion{}
+	query := "SELECT * FROM promotions WHERE stand_id=$1"
+	if filters["is_valid"] != nil {
+		query += " AND starts_at <= CURRENT_TIMESTAMP AND ends_at > CURRENT_TIMESTAMP AND (quantity_limit IS NULL OR used_quantity < quantity_limit)"
+	}
+	query += " ORDER BY starts_at, id"
+	err := s.db.Select(&promotions, query, standId)
+
+	return promotions, err
+}
+
+// FindAllValidForUpdate locks the promotions of the stand that can be applied
+// at the given time, so concurrent purchases can't go over their quantity
+// limit.
+func (s *Repository) FindAllValidForUpdate(standId int, at time.Time) ([]models.Promotion, error) {
+	promotions := []models.Promotion{}
+	query := `
+		SELECT *
+		FROM promotions
+		WHERE stand_id=$1
+			AND starts_at <= $2
+			AND ends_at > $2
+			AND (quantity_limit IS NULL OR used_quantity < quantity_limit)
+		ORDER BY id
+		FOR UPDATE
+	`
+	err := s.db.Select(&promotions, query, standId, at)
+
+	return promotions, err
+}
+
+func (s *Repository) FindById(id int) (models.Promotion, error) {
+	promotion := models.Promotion{}
+	query := "SELECT * FROM promotions WHERE id=$1"
+	err := s.db.Get(&promotion, query, id)
+
+	return promotion, err
+}
+
+func (s *Repository) Create(input map[string]interface{}) error {
+	query := "INSERT INTO promotions (stand_id, product_id, name, type, value, starts_at, ends_at, quantity_limit) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
+	_, err := s.db.Exec(query, input["stand_id"], input["product_id"], input["name"], input["type"], input["value"], input["starts_at"], input["ends_at"], input["quantity_limit"])
+
+	return err
+}
+
+func (s *Repository) Update(id int, input map[string]interface{}) error {
+	query := "UPDATE promotions SET product_id=$1, name=$2, type=$3, value=$4, starts_at=$5, ends_at=$6, quantity_limit=$7 WHERE id=$8"
+	_, err := s.db.Exec(query, input["product_id"], input["name"], input["type"], input["value"], input["starts_at"], input["ends_at"], input["quantity_limit"], id)
+
+	return err
+}
+
+func (s *Repository) Delete(id int) error {
+	query := "DELETE FROM promotions WHERE id=$1"
+	_, err := s.db.Exec(query, id)
+
+	return err
+}
+
+// Use counts quantity more units sold with the promotion.
+func (s *Repository) Use(id int, quantity int) error {
+	query := "UPDATE promotions SET used_quantity = used_quantity + $1 WHERE id=$2"
+	_, err := s.db.Exec(query, quantity, id)
+
+	return err
+}
+
+// Release gives back quantity units of a purchase that was undone.
+func (s *Repository) Release(id int, quantity int) error {
+	query := "UPDATE promotions SET used_quantity = GREATEST(used_quantity - $1, 0) WHERE id=$2"
+	_, err := s.db.Exec(query, quantity, id)
+
+	return err
+}
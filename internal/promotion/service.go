@@ -0,0 +1,362 @@
+package promotion
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+	"strings"
+	"time"
+
+	"standmaster/internal/models"
+	"standmaster/internal/product"
+	"standmaster/internal/stand"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/utils"
+)
+
+type PromotionService interface {
+	GetAll(ctx context.Context, standId int) ([]models.Promotion, error)
+	Create(ctx context.Context, standId int, input map[string]interface{}) error
+	Update(ctx context.Context, standId int, id int, input map[string]interface{}) error
+	Delete(ctx context.Context, standId int, id int) error
+}
+
+type Service struct {
+	repository        PromotionRepository
+	standRepository   stand.StandRepository
+	productRepository product.ProductRepository
+}
+
+func NewService(repository PromotionRepository, standRepository stand.StandRepository, productRepository product.ProductRepository) *Service {
+	return &Service{
+		repository:        repository,
+		standRepository:   standRepository,
+		productRepository: productRepository,
+	}
+}
+
+// GetAll returns the promotions of the stand, the ones that can't be applied
+// now are only listed to the members of the stand.
+func (s *Service) GetAll(ctx context.Context, standId int) ([]models.Promotion, error) {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return nil, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	stand, err := s.standRepository.FindById(standId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	member, err := s.standRepository.FindMemberByUserId(userId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	filters := map[string]interface{}{}
+	if member.StandId != stand.Id {
+		filters["is_valid"] = true
+	}
+
+	promotions, err := s.repository.FindAllByStandId(standId, filters)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return promotions, nil
+}
+
+func (s *Service) Create(ctx context.Context, standId int, input map[string]interface{}) error {
+	if err := s.checkOwner(ctx, standId); err != nil {
+		return err
+	}
+
+	promotion := models.Promotion{StandId: standId}
+	if err := s.parse(input, &promotion); err != nil {
+		return err
+	}
+
+	err := s.repository.Create(map[string]interface{}{
+		"stand_id":       standId,
+		"product_id":     promotion.ProductId,
+		"name":           promotion.Name,
+		"type":           promotion.Type,
+		"value":          promotion.Value,
+		"starts_at":      promotion.StartsAt,
+		"ends_at":        promotion.EndsAt,
+		"quantity_limit": promotion.QuantityLimit,
+	})
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// Update changes the given fields of a promotion, setting ends_at to now ends
+// it early.
+func (s *Service) Update(ctx context.Context, standId int, id int, input map[string]interface{}) error {
+	if err := s.checkOwner(ctx, standId); err != nil {
+		return err
+	}
+
+	promotion, err := s.find(standId, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.parse(input, &promotion); err != nil {
+		return err
+	}
+
+	err = s.repository.Update(id, map[string]interface{}{
+		"product_id":     promotion.ProductId,
+		"name":           promotion.Name,
+		"type":           promotion.Type,
+		"value":          promotion.Value,
+		"starts_at":      promotion.StartsAt,
+		"ends_at":        promotion.EndsAt,
+		"quantity_limit": promotion.QuantityLimit,
+	})
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// Delete removes a promotion that was never applied, the others stay on the
+// interactions they discounted and can only be ended.
+func (s *Service) Delete(ctx context.Context, standId int, id int) error {
+	if err := s.checkOwner(ctx, standId); err != nil {
+		return err
+	}
+
+	promotion, err := s.find(standId, id)
+	if err != nil {
+		return err
+	}
+	if promotion.UsedQuantity > 0 {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("promotion was already applied, end it instead"),
+		}
+	}
+
+	if err := s.repository.Delete(id); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) find(standId int, id int) (models.Promotion, error) {
+	promotion, err := s.repository.FindById(id)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return promotion, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return promotion, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if promotion.StandId != standId {
+		return promotion, errors.CustomError{
+			Key: errors.NotFound,
+			Err: goErrors.New("promotion not found"),
+		}
+	}
+
+	return promotion, nil
+}
+
+// checkOwner makes sure the user is an owner of the stand, cashiers can't
+// change the prices.
+func (s *Service) checkOwner(ctx context.Context, standId int) error {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	stand, err := s.standRepository.FindById(standId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	member, err := s.standRepository.FindMemberByUserId(userId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if member.StandId != stand.Id || member.Role != models.StandMemberRoleOwner {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not an owner of the stand"),
+		}
+	}
+
+	return nil
+}
+
+// parse sets the fields given in input on promotion, a new promotion needs
+// all of them but product_id and quantity_limit. product_id and
+// quantity_limit can be set to null to apply to the whole stand or without
+// limit.
+func (s *Service) parse(input map[string]interface{}, promotion *models.Promotion) error {
+	isNew := promotion.Id == 0
+
+	if input["name"] != nil || isNew {
+		name, _ := input["name"].(string)
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("name is missing"),
+			}
+		}
+		promotion.Name = name
+	}
+
+	if input["type"] != nil || isNew {
+		promotionType, _ := input["type"].(string)
+		if promotionType != models.PromotionTypePercentage && promotionType != models.PromotionTypeFixed {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("type must be PERCENTAGE or FIXED"),
+			}
+		}
+		promotion.Type = promotionType
+	}
+
+	if input["value"] != nil || isNew {
+		value, err := utils.GetIntFromMap(input, "value")
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		promotion.Value = value
+	}
+	if promotion.Value <= 0 || (promotion.Type == models.PromotionTypePercentage && promotion.Value > 100) {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("value is out of range"),
+		}
+	}
+
+	for key, value := range map[string]*time.Time{"starts_at": &promotion.StartsAt, "ends_at": &promotion.EndsAt} {
+		if input[key] == nil && !isNew {
+			continue
+		}
+		text, _ := input[key].(string)
+		date, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New(key + " is not a valid date"),
+			}
+		}
+		*value = date
+	}
+	if !promotion.EndsAt.After(promotion.StartsAt) {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("ends_at must be after starts_at"),
+		}
+	}
+
+	if _, ok := input["quantity_limit"]; ok {
+		promotion.QuantityLimit = nil
+		if input["quantity_limit"] != nil {
+			limit, err := utils.GetIntFromMap(input, "quantity_limit")
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.BadRequest,
+					Err: err,
+				}
+			}
+			if limit <= 0 {
+				return errors.CustomError{
+					Key: errors.BadRequest,
+					Err: goErrors.New("quantity_limit must be positive"),
+				}
+			}
+			promotion.QuantityLimit = &limit
+		}
+	}
+
+	if _, ok := input["product_id"]; ok {
+		promotion.ProductId = nil
+		if input["product_id"] != nil {
+			productId, err := utils.GetIntFromMap(input, "product_id")
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.BadRequest,
+					Err: err,
+				}
+			}
+			product, err := s.productRepository.FindById(productId)
+			if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+			if product.StandId != promotion.StandId {
+				return errors.CustomError{
+					Key: errors.BadRequest,
+					Err: goErrors.New("product is not sold by the stand"),
+				}
+			}
+			promotion.ProductId = &productId
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package queue
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+	"standmaster/third_party/database"
+)
+
+type QueueRepository interface {
+	WithTx(tx *sqlx.Tx) QueueRepository
+	FindAll(filters map[string]interface{}) ([]models.QueueEntry, error)
+	FindById(id int) (models.QueueEntry, error)
+	FindByIdForUpdate(id int) (models.QueueEntry, error)
+	FindNextForUpdate(standId int) (models.QueueEntry, error)
+	IsWaiting(standId int, userId int) (bool, error)
+	Position(entry models.QueueEntry) (int, error)
+	AverageDuration(standId int) (int, error)
+	Create(input map[string]interface{}) (int, error)
+	Update(id int, input map[string]interface{}) error
+}
+
+type Repository struct {
+	db database.Queryer
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) WithTx(tx *sqlx.Tx) QueueRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
+const selectEntries = `
+	SELECT
+		q.id AS id,
+		q.stand_id AS stand_id,
+		q.kermesse_id AS kermesse_id,
+		q.user_id AS user_id,
+		u.name AS user_name,
+		q.created_by AS created_by,
+		q.status AS status,
+		q.interaction_id AS interaction_id,
+		q.error AS error,
+		q.called_at AS called_at,
+		q.created_at AS created_at
+	FROM queue_entries q
+	JOIN users u ON q.user_id = u.id
+`
+
+func (s *Repository) FindAll(filters map[string]interface{}) ([]models.QueueEntry, error) {
+	entries := []models.QueueEntry{}
+	query := selectEntries + " WHERE 1=1"
+	if filters["stand_id"] != nil {
+		query += fmt.Sprintf(" AND q.stand_id = %v", filters["stand_id"])
+	}
+	if filters["user_id"] != nil {
+		query += fmt.Sprintf(" AND q.user_id = %v", filters["user_id"])
+	}
+	if filters["parent_id"] != nil {
+		query += fmt.Sprintf(" AND u.parent_id = %v", filters["parent_id"])
+	}
+	if filters["status"] != nil {
+		query += fmt.Sprintf(" AND q.status = '%v'", filters["status"])
+	}
+	query += " ORDER BY q.id"
+	err := s.db.Select(&entries, query)
+
+	return entries, err
+}
+
+func (s *Repository) FindById(id int) (models.QueueEntry, error) {
+	entry := models.QueueEntry{}
+	query := selectEntries + " WHERE q.id=$1"
+	err := s.db.Get(&entry, query, id)
+
+	return entry, err
+}
+
+func (s *Repository) FindByIdForUpdate(id int) (models.QueueEntry, error) {
+	entry := models.QueueEntry{}
+	query := selectEntries + " WHERE q.id=$1 FOR UPDATE OF q"
+	err := s.db.Get(&entry, query, id)
+
+	return entry, err
+}
+
+// FindNextForUpdate locks the first waiting entry of the stand, entries
+// locked by another call are skipped so two members can't call the same
+// child.
+func (s *Repository) FindNextForUpdate(standId int) (models.QueueEntry, error) {
+	entry := models.QueueEntry{}
+	query := selectEntries + `
+		WHERE q.stand_id=$1 AND q.status=$2
+		ORDER BY q.id
+		LIMIT 1
+		FOR UPDATE OF q SKIP LOCKED
+	`
+	err := s.db.Get(&entry, query, standId, models.QueueEntryStatusWaiting)
+
+	return entry, err
+}
+
+func (s *Repository) IsWaiting(standId int, userId int) (bool, error) {
+	var isWaiting bool
+	query := "SELECT EXISTS ( SELECT 1 FROM queue_entries WHERE stand_id=$1 AND user_id=$2 AND status=$3 )"
+	err := s.db.Get(&isWaiting, query, standId, userId, models.QueueEntryStatusWaiting)
+
+	return isWaiting, err
+}
+
+// Position returns the rank of a waiting entry in the queue of its stand,
+// starting at 1.
+func (s *Repository) Position(entry models.QueueEntry) (int, error) {
+	var position int
+	query := "SELECT COUNT(*) FROM queue_entries WHERE stand_id=$1 AND status=$2 AND id <= $3"
+	err := s.db.Get(&position, query, entry.StandId, models.QueueEntryStatusWaiting, entry.Id)
+
+	return position, err
+}
+
+// AverageDuration returns the average duration in seconds of the ended
+// interactions of the stand, 0 when none ended yet.
+func (s *Repository) AverageDuration(standId int) (int, error) {
+	var duration int
+	query := `
+		SELECT COALESCE(ROUND(EXTRACT(EPOCH FROM AVG(ended_at - created_at))), 0)::INTEGER
+		FROM interactions
+		WHERE stand_id=$1 AND ended_at IS NOT NULL
+	`
+	err := s.db.Get(&duration, query, standId)
+
+	return duration, err
+}
+
+func (s *Repository) Create(input map[string]interface{}) (int, error) {
+	var id int
+	query := "INSERT INTO queue_entries (stand_id, kermesse_id, user_id, created_by) VALUES ($1, $2, $3, $4) RETURNING id"
+	err := s.db.QueryRow(query, input["stand_id"], input["kermesse_id"], input["user_id"], input["created_by"]).Scan(&id)
+
+	return id, err
+}
+
+// Update sets the status of the entry, called_at is set the first time the
+// entry is called.
+func (s *Repository) Update(id int, input map[string]interface{}) error {
+	query := `
+		UPDATE queue_entries
+		SET
+			status=$1,
+			interaction_id=$2,
+			error=$3,
+			called_at=CASE WHEN $4 THEN COALESCE(called_at, CURRENT_TIMESTAMP) ELSE called_at END
+		WHERE id=$5
+	`
+	isCalled := input["status"] == models.QueueEntryStatusCalled
+	_, err := s.db.Exec(query, input["status"], input["interaction_id"], input["error"], isCalled, id)
+
+	return err
+}
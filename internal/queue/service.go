@@ -0,0 +1,507 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/interaction"
+	"standmaster/internal/models"
+	"standmaster/internal/stand"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/utils"
+	"standmaster/third_party/database"
+)
+
+type QueueService interface {
+	GetAll(ctx context.Context, standId int) ([]models.QueueEntry, error)
+	GetMine(ctx context.Context) ([]models.QueueEntry, error)
+	Join(ctx context.Context, standId int, input map[string]interface{}) (models.QueueEntry, error)
+	Leave(ctx context.Context, id int) error
+	CallNext(ctx context.Context, standId int) (models.QueueEntry, error)
+	NoShow(ctx context.Context, standId int, id int) error
+}
+
+type Service struct {
+	repository            QueueRepository
+	standRepository       stand.StandRepository
+	userRepository        user.UserRepository
+	interactionRepository interaction.InteractionRepository
+	interactionService    interaction.InteractionService
+	transactor            database.Transactor
+}
+
+func NewService(repository QueueRepository, standRepository stand.StandRepository, userRepository user.UserRepository, interactionRepository interaction.InteractionRepository, interactionService interaction.InteractionService, transactor database.Transactor) *Service {
+	return &Service{
+		repository:            repository,
+		standRepository:       standRepository,
+		userRepository:        userRepository,
+		interactionRepository: interactionRepository,
+		interactionService:    interactionService,
+		transactor:            transactor,
+	}
+}
+
+// GetAll lists the children waiting at the stand to its members.
+func (s *Service) GetAll(ctx context.Context, standId int) ([]models.QueueEntry, error) {
+	if err := s.checkMember(ctx, standId); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repository.FindAll(map[string]interface{}{
+		"stand_id": standId,
+		"status":   models.QueueEntryStatusWaiting,
+	})
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := s.estimate(entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetMine lists the queues the child, or the children of the parent, are
+// waiting in.
+func (s *Service) GetMine(ctx context.Context) ([]models.QueueEntry, error) {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return nil, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+	userRole, ok := ctx.Value(models.UserRoleKey).(string)
+	if !ok {
+		return nil, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user role not found in context"),
+		}
+	}
+
+	filters := map[string]interface{}{
+		"status": models.QueueEntryStatusWaiting,
+	}
+	if userRole == models.UserRoleParent {
+		filters["parent_id"] = userId
+	} else {
+		filters["user_id"] = userId
+	}
+
+	entries, err := s.repository.FindAll(filters)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := s.estimate(entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Join adds a child to the queue of an activity stand, a parent joins for
+// the child given in user_id.
+func (s *Service) Join(ctx context.Context, standId int, input map[string]interface{}) (models.QueueEntry, error) {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return models.QueueEntry{}, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+	userRole, ok := ctx.Value(models.UserRoleKey).(string)
+	if !ok {
+		return models.QueueEntry{}, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user role not found in context"),
+		}
+	}
+
+	kermesseId, err := utils.GetIntFromMap(input, "kermesse_id")
+	if err != nil {
+		return models.QueueEntry{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+
+	childId := userId
+	if userRole == models.UserRoleParent {
+		childId, err = utils.GetIntFromMap(input, "user_id")
+		if err != nil {
+			return models.QueueEntry{}, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		if err := s.checkParent(userId, childId); err != nil {
+			return models.QueueEntry{}, err
+		}
+	}
+
+	stand, err := s.standRepository.FindById(standId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return models.QueueEntry{}, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return models.QueueEntry{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if stand.Type != models.StandTypeActivity {
+		return models.QueueEntry{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("stand is not an activity"),
+		}
+	}
+
+	canCreate, err := s.interactionRepository.CanCreate(map[string]interface{}{
+		"user_id":  childId,
+		"stand_id": standId,
+	})
+	if err != nil {
+		return models.QueueEntry{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if !canCreate {
+		return models.QueueEntry{}, errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("forbidden"),
+		}
+	}
+
+	isWaiting, err := s.repository.IsWaiting(standId, childId)
+	if err != nil {
+		return models.QueueEntry{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if isWaiting {
+		return models.QueueEntry{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("child is already in the queue"),
+		}
+	}
+
+	id, err := s.repository.Create(map[string]interface{}{
+		"stand_id":    standId,
+		"kermesse_id": kermesseId,
+		"user_id":     childId,
+		"created_by":  userId,
+	})
+	if err != nil {
+		return models.QueueEntry{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return s.get(id)
+}
+
+// Leave removes a waiting child from the queue, on behalf of the child or
+// its parent.
+func (s *Service) Leave(ctx context.Context, id int) error {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+
+		entry, err := findWaiting(repository, id)
+		if err != nil {
+			return err
+		}
+		if entry.UserId != userId {
+			if err := s.checkParent(userId, entry.UserId); err != nil {
+				return err
+			}
+		}
+
+		err = repository.Update(id, map[string]interface{}{
+			"status": models.QueueEntryStatusLeft,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+// CallNext takes the first child of the queue and charges the activity, the
+// entry is marked FAILED with the reason when the child can't be charged.
+func (s *Service) CallNext(ctx context.Context, standId int) (models.QueueEntry, error) {
+	if err := s.checkMember(ctx, standId); err != nil {
+		return models.QueueEntry{}, err
+	}
+
+	// mark the entry as called first so it can't be called twice
+	var entry models.QueueEntry
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+
+		var err error
+		entry, err = repository.FindNextForUpdate(standId)
+		if err != nil {
+			if goErrors.Is(err, sql.ErrNoRows) {
+				return errors.CustomError{
+					Key: errors.NotFound,
+					Err: goErrors.New("queue is empty"),
+				}
+			}
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		err = repository.Update(entry.Id, map[string]interface{}{
+			"status": models.QueueEntryStatusCalled,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.QueueEntry{}, errors.FromError(err)
+	}
+
+	charged, chargeErr := s.interactionService.CreateForUser(ctx, entry.UserId, map[string]interface{}{
+		"stand_id":    standId,
+		"kermesse_id": entry.KermesseId,
+	})
+	if chargeErr != nil {
+		message := chargeErr.Error()
+		err = s.repository.Update(entry.Id, map[string]interface{}{
+			"status": models.QueueEntryStatusFailed,
+			"error":  message,
+		})
+		if err != nil {
+			return models.QueueEntry{}, errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		return models.QueueEntry{}, chargeErr
+	}
+
+	err = s.repository.Update(entry.Id, map[string]interface{}{
+		"status":         models.QueueEntryStatusCalled,
+		"interaction_id": charged.Id,
+	})
+	if err != nil {
+		return models.QueueEntry{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return s.get(entry.Id)
+}
+
+// NoShow lets a member of the stand skip a waiting child who isn't there,
+// the child isn't charged.
+func (s *Service) NoShow(ctx context.Context, standId int, id int) error {
+	if err := s.checkMember(ctx, standId); err != nil {
+		return err
+	}
+
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+
+		entry, err := findWaiting(repository, id)
+		if err != nil {
+			return err
+		}
+		if entry.StandId != standId {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: goErrors.New("entry not found"),
+			}
+		}
+
+		err = repository.Update(id, map[string]interface{}{
+			"status": models.QueueEntryStatusNoShow,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+func (s *Service) get(id int) (models.QueueEntry, error) {
+	entry, err := s.repository.FindById(id)
+	if err != nil {
+		return entry, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	entries := []models.QueueEntry{entry}
+	if err := s.estimate(entries); err != nil {
+		return entry, err
+	}
+
+	return entries[0], nil
+}
+
+// estimate sets the position of the waiting entries and their estimated
+// wait, the average duration of the activity times the children before them
+// and the one being served. The wait is unknown until an activity ended.
+func (s *Service) estimate(entries []models.QueueEntry) error {
+	durations := map[int]int{}
+	for i := range entries {
+		if entries[i].Status != models.QueueEntryStatusWaiting {
+			continue
+		}
+
+		position, err := s.repository.Position(entries[i])
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		entries[i].Position = position
+
+		duration, ok := durations[entries[i].StandId]
+		if !ok {
+			duration, err = s.repository.AverageDuration(entries[i].StandId)
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+			durations[entries[i].StandId] = duration
+		}
+		if duration > 0 {
+			wait := duration * position
+			entries[i].EstimatedWait = &wait
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) checkMember(ctx context.Context, standId int) error {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	member, err := s.standRepository.FindMemberByUserId(userId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if member.StandId != standId {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not a member of the stand"),
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) checkParent(parentId int, childId int) error {
+	child, err := s.userRepository.FindById(childId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if child.ParentId == nil || *child.ParentId != parentId {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not the parent of the child"),
+		}
+	}
+
+	return nil
+}
+
+func findWaiting(repository QueueRepository, id int) (models.QueueEntry, error) {
+	entry, err := repository.FindByIdForUpdate(id)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return entry, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return entry, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if entry.Status != models.QueueEntryStatusWaiting {
+		return entry, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("child is not waiting"),
+		}
+	}
+
+	return entry, nil
+}
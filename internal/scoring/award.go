@@ -0,0 +1,71 @@
+package scoring
+
+import (
+	"database/sql"
+	goErrors "errors"
+	"fmt"
+
+	"standmaster/pkg/errors"
+	"standmaster/pkg/utils"
+)
+
+// Award returns the points given in input for an activity of the stand and
+// the awarded tier, if any. Without a rule any point value is accepted, with
+// one the points must be in its bounds, or be one of its tiers when it has
+// some, and the participation bonus is added.
+func Award(repository ScoringRepository, kermesseId int, standId int, input map[string]interface{}) (int, *string, error) {
+	rule, err := repository.FindByStand(kermesseId, standId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return 0, nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if goErrors.Is(err, sql.ErrNoRows) {
+		point, err := utils.GetIntFromMap(input, "point")
+		if err != nil {
+			return 0, nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		return point, nil, nil
+	}
+
+	tiers, err := repository.FindAllTiers(rule.Id)
+	if err != nil {
+		return 0, nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if len(tiers) > 0 {
+		name, _ := input["tier"].(string)
+		for _, tier := range tiers {
+			if tier.Name == name {
+				return tier.Points + rule.ParticipationBonus, &tier.Name, nil
+			}
+		}
+		return 0, nil, errors.CustomError{
+			Key: errors.InvalidScore,
+			Err: fmt.Errorf("tier %q is not awarded by the stand", name),
+		}
+	}
+
+	point, err := utils.GetIntFromMap(input, "point")
+	if err != nil {
+		return 0, nil, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+	if point < rule.MinPoints || point > rule.MaxPoints {
+		return 0, nil, errors.CustomError{
+			Key: errors.InvalidScore,
+			Err: fmt.Errorf("point must be between %d and %d", rule.MinPoints, rule.MaxPoints),
+		}
+	}
+
+	return point + rule.ParticipationBonus, nil, nil
+}
@@ -0,0 +1,86 @@
+package scoring
+
+import (
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+	"standmaster/third_party/database"
+)
+
+type ScoringRepository interface {
+	WithTx(tx *sqlx.Tx) ScoringRepository
+	FindByStand(kermesseId int, standId int) (models.ScoringRule, error)
+	Save(input map[string]interface{}) (int, error)
+	Delete(id int) error
+
+	FindAllTiers(ruleId int) ([]models.ScoringTier, error)
+	CreateTier(input map[string]interface{}) error
+	DeleteTiers(ruleId int) error
+}
+
+type Repository struct {
+	db database.Queryer
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) WithTx(tx *sqlx.Tx) ScoringRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
+func (s *Repository) FindByStand(kermesseId int, standId int) (models.ScoringRule, error) {
+	rule := models.ScoringRule{}
+	query := "SELECT * FROM scoring_rules WHERE kermesse_id=$1 AND stand_id=$2"
+	err := s.db.Get(&rule, query, kermesseId, standId)
+
+	return rule, err
+}
+
+// Save creates the rule of the stand in the kermesse or replaces its bounds.
+func (s *Repository) Save(input map[string]interface{}) (int, error) {
+	var id int
+	query := `
+		INSERT INTO scoring_rules (kermesse_id, stand_id, min_points, max_points, participation_bonus)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (kermesse_id, stand_id) DO UPDATE
+		SET min_points=EXCLUDED.min_points, max_points=EXCLUDED.max_points, participation_bonus=EXCLUDED.participation_bonus
+		RETURNING id
+	`
+	err := s.db.QueryRow(query, input["kermesse_id"], input["stand_id"], input["min_points"], input["max_points"], input["participation_bonus"]).Scan(&id)
+
+	return id, err
+}
+
+func (s *Repository) Delete(id int) error {
+	query := "DELETE FROM scoring_rules WHERE id=$1"
+	_, err := s.db.Exec(query, id)
+
+	return err
+}
+
+func (s *Repository) FindAllTiers(ruleId int) ([]models.ScoringTier, error) {
+	tiers := []models.ScoringTier{}
+	query := "SELECT * FROM scoring_tiers WHERE scoring_rule_id=$1 ORDER BY points, id"
+	err := s.db.Select(&tiers, query, ruleId)
+
+	return tiers, err
+}
+
+func (s *Repository) CreateTier(input map[string]interface{}) error {
+	query := "INSERT INTO scoring_tiers (scoring_rule_id, name, points) VALUES ($1, $2, $3)"
+	_, err := s.db.Exec(query, input["scoring_rule_id"], input["name"], input["points"])
+
+	return err
+}
+
+func (s *Repository) DeleteTiers(ruleId int) error {
+	query := "DELETE FROM scoring_tiers WHERE scoring_rule_id=$1"
+	_, err := s.db.Exec(query, ruleId)
+
+	return err
+}
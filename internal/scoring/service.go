@@ -0,0 +1,335 @@
+package scoring
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/kermesse"
+	"standmaster/internal/models"
+	"standmaster/internal/stand"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/utils"
+	"standmaster/third_party/database"
+)
+
+type ScoringService interface {
+	Get(ctx context.Context, kermesseId int, standId int) (models.ScoringRule, error)
+	Save(ctx context.Context, kermesseId int, standId int, input map[string]interface{}) error
+	Delete(ctx context.Context, kermesseId int, standId int) error
+}
+
+type Service struct {
+	repository         ScoringRepository
+	kermesseRepository kermesse.KermesseRepository
+	standRepository    stand.StandRepository
+	transactor         database.Transactor
+}
+
+func NewService(repository ScoringRepository, kermesseRepository kermesse.KermesseRepository, standRepository stand.StandRepository, transactor database.Transactor) *Service {
+	return &Service{
+		repository:         repository,
+		kermesseRepository: kermesseRepository,
+		standRepository:    standRepository,
+		transactor:         transactor,
+	}
+}
+
+func (s *Service) Get(ctx context.Context, kermesseId int, standId int) (models.ScoringRule, error) {
+	rule, err := s.repository.FindByStand(kermesseId, standId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return rule, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return rule, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	rule.Tiers, err = s.repository.FindAllTiers(rule.Id)
+	if err != nil {
+		return rule, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return rule, nil
+}
+
+// Save lets the organizer set the rule of an activity stand of the kermesse,
+// fields missing from input keep their value and tiers, when given, replace
+// the previous ones.
+func (s *Service) Save(ctx context.Context, kermesseId int, standId int, input map[string]interface{}) error {
+	if err := s.checkOrganizer(ctx, kermesseId, standId); err != nil {
+		return err
+	}
+
+	rule, err := s.repository.FindByStand(kermesseId, standId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if rule.Id == 0 && input["max_points"] == nil {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("max_points is missing"),
+		}
+	}
+	for key, value := range map[string]*int{"min_points": &rule.MinPoints, "max_points": &rule.MaxPoints, "participation_bonus": &rule.ParticipationBonus} {
+		if input[key] == nil {
+			continue
+		}
+		n, err := utils.GetIntFromMap(input, key)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		if n < 0 {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New(key + " can't be negative"),
+			}
+		}
+		*value = n
+	}
+	if rule.MaxPoints < rule.MinPoints {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("max_points is lower than min_points"),
+		}
+	}
+
+	tiers, err := parseTiers(input, rule)
+	if err != nil {
+		return err
+	}
+	// the tiers kept must fit in the new bounds too
+	if tiers == nil && rule.Id != 0 {
+		kept, err := s.repository.FindAllTiers(rule.Id)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		for _, tier := range kept {
+			if tier.Points < rule.MinPoints || tier.Points > rule.MaxPoints {
+				return errors.CustomError{
+					Key: errors.BadRequest,
+					Err: goErrors.New("tier points are out of the bounds of the rule"),
+				}
+			}
+		}
+	}
+
+	err = s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+
+		id, err := repository.Save(map[string]interface{}{
+			"kermesse_id":         kermesseId,
+			"stand_id":            standId,
+			"min_points":          rule.MinPoints,
+			"max_points":          rule.MaxPoints,
+			"participation_bonus": rule.ParticipationBonus,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		if tiers == nil {
+			return nil
+		}
+		if err := repository.DeleteTiers(id); err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		for _, tier := range tiers {
+			err = repository.CreateTier(map[string]interface{}{
+				"scoring_rule_id": id,
+				"name":            tier.Name,
+				"points":          tier.Points,
+			})
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+// Delete removes the rule, the stand can award any points again.
+func (s *Service) Delete(ctx context.Context, kermesseId int, standId int) error {
+	if err := s.checkOrganizer(ctx, kermesseId, standId); err != nil {
+		return err
+	}
+
+	rule, err := s.repository.FindByStand(kermesseId, standId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	if err := s.repository.Delete(rule.Id); err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// checkOrganizer makes sure the user organizes the kermesse and the stand is
+// one of its activity stands.
+func (s *Service) checkOrganizer(ctx context.Context, kermesseId int, standId int) error {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	kermesse, err := s.kermesseRepository.FindById(kermesseId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if kermesse.UserId != userId {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not the organizer of the kermesse"),
+		}
+	}
+
+	hasStand, err := s.kermesseRepository.HasStand(kermesseId, standId)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if !hasStand {
+		return errors.CustomError{
+			Key: errors.NotFound,
+			Err: goErrors.New("stand is not in the kermesse"),
+		}
+	}
+
+	stand, err := s.standRepository.FindById(standId)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if stand.Type != models.StandTypeActivity {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("stand is not an activity"),
+		}
+	}
+
+	return nil
+}
+
+// parseTiers reads the tiers given in input as [{name, points}], nil when
+// input has none. Each tier must fit in the bounds of the rule.
+func parseTiers(input map[string]interface{}, rule models.ScoringRule) ([]models.ScoringTier, error) {
+	if input["tiers"] == nil {
+		return nil, nil
+	}
+
+	values, ok := input["tiers"].([]interface{})
+	if !ok {
+		return nil, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("tiers is not a valid list"),
+		}
+	}
+
+	tiers := []models.ScoringTier{}
+	names := map[string]bool{}
+	for _, value := range values {
+		line, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("tier is not a valid object"),
+			}
+		}
+
+		name, _ := line["name"].(string)
+		name = strings.TrimSpace(name)
+		if name == "" || names[name] {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("tier name is missing or duplicated"),
+			}
+		}
+		names[name] = true
+
+		points, err := utils.GetIntFromMap(line, "points")
+		if err != nil {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		if points < rule.MinPoints || points > rule.MaxPoints {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("tier points are out of the bounds of the rule"),
+			}
+		}
+
+		tiers = append(tiers, models.ScoringTier{
+			Name:   name,
+			Points: points,
+		})
+	}
+
+	return tiers, nil
+}
@@ -2,23 +2,39 @@ package stand
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"standmaster/internal/models"
+	"standmaster/third_party/database"
 )
 
 type StandRepository interface {
+	WithTx(tx *sqlx.Tx) StandRepository
 	FindAll(filters map[string]interface{}) ([]models.Stand, error)
 	FindById(id int) (models.Stand, error)
+	FindByIdForUpdate(id int) (models.Stand, error)
 	FindByUserId(id int) (models.Stand, error)
-	Create(input map[string]interface{}) error
+	Create(input map[string]interface{}) (int, error)
 	Update(id int, input map[string]interface{}) error
 	UpdateByUserId(userId int, input map[string]interface{}) error
-	UpdateStock(id int, n int) error
+	FindAllMembers(standId int) ([]models.StandMember, error)
+	FindMemberByUserId(userId int) (models.StandMember, error)
+	CreateMember(input map[string]interface{}) error
+	DeleteMember(standId int, userId int) error
+	UpdatePause(id int, isPaused bool) error
+	UpdateImage(id int, image models.Image) error
+
+	FindAllSchedules(standId int, kermesseId int) ([]models.StandSchedule, error)
+	FindScheduleById(id int) (models.StandSchedule, error)
+	FindOpening(id int, kermesseId int, at time.Time) (models.StandOpening, error)
+	CreateSchedule(input map[string]interface{}) error
+	DeleteSchedule(id int) error
 }
 
 type Repository struct {
-	db *sqlx.DB
+	db database.Queryer
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
@@ -27,6 +43,12 @@ func NewRepository(db *sqlx.DB) *Repository {
 	}
 }
 
+func (s *Repository) WithTx(tx *sqlx.Tx) StandRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
 func (s *Repository) FindAll(filters map[string]interface{}) ([]models.Stand, error) {
 	stands := []models.Stand{}
 	query := `
@@ -37,7 +59,12 @@ func (s *Repository) FindAll(filters map[string]interface{}) ([]models.Stand, er
 			s.description AS description,
 			s.type AS type,
 			s.price AS price,
-			s.stock AS stock
+			s.stock AS stock,
+			s.low_stock_threshold AS low_stock_threshold,
+			s.stock_alert AS stock_alert,
+			s.is_paused AS is_paused,
+			s.image_url AS image_url,
+			s.thumbnail_url AS thumbnail_url
 		FROM stands s
 		LEFT JOIN kermesses_stands ks ON s.id = ks.stand_id
 		WHERE 1=1 AND s.id IS NOT NULL
@@ -53,12 +80,36 @@ func (s *Repository) FindAll(filters map[string]interface{}) ([]models.Stand, er
 					SELECT ks_inner.stand_id 
 					FROM kermesses_stands ks_inner
 					JOIN kermesses k ON ks_inner.kermesse_id = k.id
-					WHERE k.status = 'STARTED'
+					WHERE k.status = 'RUNNING'
 				)
 			)
     `
 	}
-	err := s.db.Select(&stands, query)
+	args := []interface{}{}
+	if filters["include_tags"] != nil {
+		names := filters["include_tags"].([]string)
+		args = append(args, pq.Array(names))
+		query += fmt.Sprintf(`
+			AND (
+				SELECT COUNT(*)
+				FROM stand_tags st
+				JOIN tags t ON st.tag_id = t.id
+				WHERE st.stand_id = s.id AND t.name = ANY($%d)
+			) = %d
+		`, len(args), len(names))
+	}
+	if filters["exclude_tags"] != nil {
+		args = append(args, pq.Array(filters["exclude_tags"].([]string)))
+		query += fmt.Sprintf(`
+			AND NOT EXISTS (
+				SELECT 1
+				FROM stand_tags st
+				JOIN tags t ON st.tag_id = t.id
+				WHERE st.stand_id = s.id AND t.name = ANY($%d)
+			)
+		`, len(args))
+	}
+	err := s.db.Select(&stands, query, args...)
 
 	return stands, err
 }
@@ -71,38 +122,169 @@ func (s *Repository) FindById(id int) (models.Stand, error) {
 	return stand, err
 }
 
+func (s *Repository) FindByIdForUpdate(id int) (models.Stand, error) {
+	stand := models.Stand{}
+	query := "SELECT * FROM stands WHERE id=$1 FOR UPDATE"
+	err := s.db.Get(&stand, query, id)
+
+	return stand, err
+}
+
 func (s *Repository) FindByUserId(userId int) (models.Stand, error) {
 	stand := models.Stand{}
-	query := "SELECT * FROM stands WHERE user_id=$1 LIMIT 1"
+	query := `
+		SELECT s.*
+		FROM stands s
+		JOIN stand_members sm ON s.id = sm.stand_id
+		WHERE sm.user_id=$1
+	`
 	err := s.db.Get(&stand, query, userId)
 
 	return stand, err
 }
 
-func (s *Repository) Create(input map[string]interface{}) error {
-	query := "INSERT INTO stands (user_id, name, description, type, price, stock) VALUES ($1, $2, $3, $4, $5, $6)"
-	_, err := s.db.Exec(query, input["user_id"], input["name"], input["description"], input["type"], input["price"], input["stock"])
+func (s *Repository) Create(input map[string]interface{}) (int, error) {
+	var id int
+	query := "INSERT INTO stands (user_id, name, description, type, price, stock) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+	err := s.db.QueryRow(query, input["user_id"], input["name"], input["description"], input["type"], input["price"], input["stock"]).Scan(&id)
 
-	return err
+	return id, err
 }
 
 func (s *Repository) Update(id int, input map[string]interface{}) error {
-	query := "UPDATE stands SET name=$1, description=$2, price=$3, stock=$4 WHERE id=$5"
-	_, err := s.db.Exec(query, input["name"], input["description"], input["price"], input["stock"], id)
+	query := "UPDATE stands SET name=$1, description=$2, price=$3 WHERE id=$4"
+	_, err := s.db.Exec(query, input["name"], input["description"], input["price"], id)
 
 	return err
 }
 
 func (s *Repository) UpdateByUserId(userId int, input map[string]interface{}) error {
-	query := "UPDATE stands SET name=$1, description=$2, price=$3, stock=$4 WHERE user_id=$5"
-	_, err := s.db.Exec(query, input["name"], input["description"], input["price"], input["stock"], userId)
+	query := `
+		UPDATE stands SET name=$1, description=$2, price=$3
+		WHERE id = (SELECT stand_id FROM stand_members WHERE user_id=$4)
+	`
+	_, err := s.db.Exec(query, input["name"], input["description"], input["price"], userId)
+
+	return err
+}
+
+func (s *Repository) FindAllMembers(standId int) ([]models.StandMember, error) {
+	members := []models.StandMember{}
+	query := `
+		SELECT
+			sm.id AS id,
+			sm.stand_id AS stand_id,
+			sm.user_id AS user_id,
+			u.name AS name,
+			u.email AS email,
+			sm.role AS role,
+			sm.created_at AS created_at
+		FROM stand_members sm
+		JOIN users u ON sm.user_id = u.id
+		WHERE sm.stand_id=$1
+		ORDER BY sm.id
+	`
+	err := s.db.Select(&members, query, standId)
+
+	return members, err
+}
+
+func (s *Repository) FindMemberByUserId(userId int) (models.StandMember, error) {
+	member := models.StandMember{}
+	query := `
+		SELECT
+			sm.id AS id,
+			sm.stand_id AS stand_id,
+			sm.user_id AS user_id,
+			u.name AS name,
+			u.email AS email,
+			sm.role AS role,
+			sm.created_at AS created_at
+		FROM stand_members sm
+		JOIN users u ON sm.user_id = u.id
+		WHERE sm.user_id=$1
+	`
+	err := s.db.Get(&member, query, userId)
+
+	return member, err
+}
+
+func (s *Repository) CreateMember(input map[string]interface{}) error {
+	query := "INSERT INTO stand_members (stand_id, user_id, role) VALUES ($1, $2, $3)"
+	_, err := s.db.Exec(query, input["stand_id"], input["user_id"], input["role"])
+
+	return err
+}
+
+func (s *Repository) DeleteMember(standId int, userId int) error {
+	query := "DELETE FROM stand_members WHERE stand_id=$1 AND user_id=$2"
+	_, err := s.db.Exec(query, standId, userId)
+
+	return err
+}
+
+func (s *Repository) UpdatePause(id int, isPaused bool) error {
+	query := "UPDATE stands SET is_paused=$1 WHERE id=$2"
+	_, err := s.db.Exec(query, isPaused, id)
+
+	return err
+}
+
+func (s *Repository) UpdateImage(id int, image models.Image) error {
+	query := "UPDATE stands SET image_url=$1, thumbnail_url=$2 WHERE id=$3"
+	_, err := s.db.Exec(query, image.URL, image.ThumbnailURL, id)
+
+	return err
+}
+
+func (s *Repository) FindAllSchedules(standId int, kermesseId int) ([]models.StandSchedule, error) {
+	schedules := []models.StandSchedule{}
+	query := "SELECT * FROM stand_schedules WHERE stand_id=$1 AND kermesse_id=$2 ORDER BY opens_at"
+	err := s.db.Select(&schedules, query, standId, kermesseId)
+
+	return schedules, err
+}
+
+func (s *Repository) FindScheduleById(id int) (models.StandSchedule, error) {
+	schedule := models.StandSchedule{}
+	query := "SELECT * FROM stand_schedules WHERE id=$1"
+	err := s.db.Get(&schedule, query, id)
+
+	return schedule, err
+}
+
+// FindOpening tells whether the stand is in an opening slot of the kermesse
+// at the given time.
+func (s *Repository) FindOpening(id int, kermesseId int, at time.Time) (models.StandOpening, error) {
+	opening := models.StandOpening{}
+	query := `
+		SELECT
+			NOT EXISTS (
+				SELECT 1 FROM stand_schedules WHERE stand_id=$1 AND kermesse_id=$2
+			) OR EXISTS (
+				SELECT 1 FROM stand_schedules
+				WHERE stand_id=$1 AND kermesse_id=$2 AND opens_at <= $3 AND closes_at > $3
+			) AS is_scheduled,
+			(
+				SELECT MIN(opens_at) FROM stand_schedules
+				WHERE stand_id=$1 AND kermesse_id=$2 AND opens_at > $3
+			) AS next_opening_at
+	`
+	err := s.db.Get(&opening, query, id, kermesseId, at)
+
+	return opening, err
+}
+
+func (s *Repository) CreateSchedule(input map[string]interface{}) error {
+	query := "INSERT INTO stand_schedules (stand_id, kermesse_id, opens_at, closes_at) VALUES ($1, $2, $3, $4)"
+	_, err := s.db.Exec(query, input["stand_id"], input["kermesse_id"], input["opens_at"], input["closes_at"])
 
 	return err
 }
 
-func (s *Repository) UpdateStock(id int, quantity int) error {
-	query := "UPDATE stands SET stock=stock+$1 WHERE id=$2"
-	_, err := s.db.Exec(query, quantity, id)
+func (s *Repository) DeleteSchedule(id int) error {
+	query := "DELETE FROM stand_schedules WHERE id=$1"
+	_, err := s.db.Exec(query, id)
 
 	return err
 }
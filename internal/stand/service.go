@@ -4,9 +4,21 @@ import (
 	"context"
 	"database/sql"
 	goErrors "errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
 
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/kermesse"
+	"standmaster/internal/media"
 	"standmaster/internal/models"
+	"standmaster/internal/stock"
+	"standmaster/internal/tag"
+	"standmaster/internal/user"
 	"standmaster/pkg/errors"
+	"standmaster/pkg/utils"
+	"standmaster/third_party/database"
 )
 
 type StandService interface {
@@ -16,28 +28,68 @@ type StandService interface {
 	Create(ctx context.Context, input map[string]interface{}) error
 	Update(ctx context.Context, id int, input map[string]interface{}) error
 	UpdateCurrent(ctx context.Context, input map[string]interface{}) error
+	GetMembers(ctx context.Context, id int) ([]models.StandMember, error)
+	AddMember(ctx context.Context, id int, input map[string]interface{}) error
+	RemoveMember(ctx context.Context, id int, userId int) error
+
+	Pause(ctx context.Context, id int, input map[string]interface{}) error
+	UploadImage(ctx context.Context, id int, file io.Reader) (models.Stand, error)
+	SetTags(ctx context.Context, id int, input map[string]interface{}) error
+	GetSchedules(ctx context.Context, id int, kermesseId int) ([]models.StandSchedule, error)
+	CreateSchedule(ctx context.Context, id int, input map[string]interface{}) error
+	DeleteSchedule(ctx context.Context, id int, scheduleId int) error
 }
 
 type Service struct {
-	repository StandRepository
+	repository         StandRepository
+	userRepository     user.UserRepository
+	tagRepository      tag.TagRepository
+	stockRepository    stock.StockRepository
+	kermesseRepository kermesse.KermesseRepository
+	mediaService       media.MediaService
+	transactor         database.Transactor
 }
 
-func NewService(repository StandRepository) *Service {
+func NewService(repository StandRepository, userRepository user.UserRepository, tagRepository tag.TagRepository, stockRepository stock.StockRepository, kermesseRepository kermesse.KermesseRepository, mediaService media.MediaService, transactor database.Transactor) *Service {
 	return &Service{
-		repository: repository,
+		repository:         repository,
+		userRepository:     userRepository,
+		tagRepository:      tagRepository,
+		stockRepository:    stockRepository,
+		kermesseRepository: kermesseRepository,
+		mediaService:       mediaService,
+		transactor:         transactor,
 	}
 }
 
+// GetAll lists the stands, include_tags and exclude_tags are comma separated
+// tag names the stands must all have or must not have.
 func (s *Service) GetAll(ctx context.Context, params map[string]interface{}) ([]models.Stand, error) {
 	filters := map[string]interface{}{}
+	kermesseId := 0
 	if params["kermesse_id"] != nil {
-		filters["kermesse_id"] = params["kermesse_id"]
+		value, _ := params["kermesse_id"].(string)
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: err,
+			}
+		}
+		kermesseId = id
+		filters["kermesse_id"] = kermesseId
 	}
 	if params["is_free"] != nil {
 		filters["is_free"] = params["is_free"]
 	}
+	for _, key := range []string{"include_tags", "exclude_tags"} {
+		value, _ := params[key].(string)
+		if names := tag.SplitNames(value); len(names) > 0 {
+			filters[key] = names
+		}
+	}
 
-	stands, err := s.repository.FindAll(params)
+	stands, err := s.repository.FindAll(filters)
 	if err != nil {
 		return nil, errors.CustomError{
 			Key: errors.InternalServerError,
@@ -45,6 +97,28 @@ func (s *Service) GetAll(ctx context.Context, params map[string]interface{}) ([]
 		}
 	}
 
+	if err := s.withTags(stands); err != nil {
+		return nil, err
+	}
+
+	// report the opening of the stands in the kermesse
+	if kermesseId != 0 {
+		for i := range stands {
+			opening, err := s.repository.FindOpening(stands[i].Id, kermesseId, time.Now())
+			if err != nil {
+				return nil, errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+			isOpen := opening.IsScheduled && !stands[i].IsPaused
+			stands[i].IsOpen = &isOpen
+			if !isOpen {
+				stands[i].NextOpeningAt = opening.NextOpeningAt
+			}
+		}
+	}
+
 	return stands, nil
 }
 
@@ -63,7 +137,12 @@ func (s *Service) Get(ctx context.Context, id int) (models.Stand, error) {
 		}
 	}
 
-	return stand, nil
+	stands := []models.Stand{stand}
+	if err := s.withTags(stands); err != nil {
+		return stand, err
+	}
+
+	return stands[0], nil
 }
 
 func (s *Service) GetCurrent(ctx context.Context) (models.Stand, error) {
@@ -89,7 +168,12 @@ func (s *Service) GetCurrent(ctx context.Context) (models.Stand, error) {
 		}
 	}
 
-	return stand, nil
+	stands := []models.Stand{stand}
+	if err := s.withTags(stands); err != nil {
+		return stand, err
+	}
+
+	return stands[0], nil
 }
 
 func (s *Service) Create(ctx context.Context, input map[string]interface{}) error {
@@ -102,17 +186,68 @@ func (s *Service) Create(ctx context.Context, input map[string]interface{}) erro
 	}
 	input["user_id"] = userId
 
-	err := s.repository.Create(input)
+	stock, err := parseStock(input)
 	if err != nil {
-		return errors.CustomError{
-			Key: errors.InternalServerError,
-			Err: err,
+		return err
+	}
+	// the initial stock is logged as the first movement of the stand
+	input["stock"] = 0
+
+	// the creator owns the stand and its wallet receives the revenue
+	err = s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+
+		if err := checkNoStand(repository, userId); err != nil {
+			return err
 		}
+
+		id, err := repository.Create(input)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		err = repository.CreateMember(map[string]interface{}{
+			"stand_id": id,
+			"user_id":  userId,
+			"role":     models.StandMemberRoleOwner,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		if stock == nil || *stock == 0 {
+			return nil
+		}
+		_, err = s.stockRepository.WithTx(tx).Create(map[string]interface{}{
+			"stand_id": id,
+			"kind":     models.StockMovementKindInitial,
+			"quantity": *stock,
+			"reason":   "stand created",
+			"user_id":  userId,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
 	}
 
 	return nil
 }
 
+// Update changes the stand, a new stock is logged as a manual adjustment.
 func (s *Service) Update(ctx context.Context, id int, input map[string]interface{}) error {
 	stand, err := s.repository.FindById(id)
 	if err != nil {
@@ -135,14 +270,223 @@ func (s *Service) Update(ctx context.Context, id int, input map[string]interface
 			Err: goErrors.New("user id not found in context"),
 		}
 	}
-	if stand.UserId != userId {
+	if err := s.checkOwner(stand.Id, userId); err != nil {
+		return err
+	}
+
+	stock, err := parseStock(input)
+	if err != nil {
+		return err
+	}
+
+	err = s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		if err := s.repository.WithTx(tx).Update(id, input); err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return s.setStock(tx, id, userId, stock)
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+func (s *Service) UpdateCurrent(ctx context.Context, input map[string]interface{}) error {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	member, err := s.repository.FindMemberByUserId(userId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if member.Role != models.StandMemberRoleOwner {
 		return errors.CustomError{
 			Key: errors.Forbidden,
-			Err: goErrors.New("user is not the holder of the stand"),
+			Err: goErrors.New("user is not an owner of the stand"),
 		}
 	}
 
-	err = s.repository.Update(id, input)
+	stock, err := parseStock(input)
+	if err != nil {
+		return err
+	}
+
+	err = s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		if err := s.repository.WithTx(tx).UpdateByUserId(userId, input); err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return s.setStock(tx, member.StandId, userId, stock)
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+// GetMembers lists the staff of a stand to its members.
+func (s *Service) GetMembers(ctx context.Context, id int) ([]models.StandMember, error) {
+	if err := s.checkMember(ctx, id); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repository.FindAllMembers(id)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return members, nil
+}
+
+// AddMember lets an owner invite a stand holder who doesn't work at a stand
+// yet, as an owner or a cashier.
+func (s *Service) AddMember(ctx context.Context, id int, input map[string]interface{}) error {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+	if err := s.checkOwner(id, userId); err != nil {
+		return err
+	}
+
+	memberId, err := utils.GetIntFromMap(input, "user_id")
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+	role, _ := input["role"].(string)
+	if role == "" {
+		role = models.StandMemberRoleCashier
+	}
+	if role != models.StandMemberRoleOwner && role != models.StandMemberRoleCashier {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("invalid role"),
+		}
+	}
+
+	err = s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+
+		holder, err := s.userRepository.WithTx(tx).FindByIdForUpdate(memberId)
+		if err != nil {
+			if goErrors.Is(err, sql.ErrNoRows) {
+				return errors.CustomError{
+					Key: errors.NotFound,
+					Err: err,
+				}
+			}
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		if holder.Role != models.UserRoleStandHolder {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("user is not a stand holder"),
+			}
+		}
+
+		if err := checkNoStand(repository, holder.Id); err != nil {
+			return err
+		}
+
+		err = repository.CreateMember(map[string]interface{}{
+			"stand_id": id,
+			"user_id":  holder.Id,
+			"role":     role,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+// RemoveMember lets an owner remove a member of the stand, the owner whose
+// wallet receives the revenue can't be removed.
+func (s *Service) RemoveMember(ctx context.Context, id int, memberId int) error {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+	if err := s.checkOwner(id, userId); err != nil {
+		return err
+	}
+
+	stand, err := s.repository.FindById(id)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if stand.UserId == memberId {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("the wallet owner of the stand can't be removed"),
+		}
+	}
+
+	member, err := s.repository.FindMemberByUserId(memberId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if member.StandId != id {
+		return errors.CustomError{
+			Key: errors.NotFound,
+			Err: goErrors.New("user is not a member of the stand"),
+		}
+	}
+
+	err = s.repository.DeleteMember(id, memberId)
 	if err != nil {
 		return errors.CustomError{
 			Key: errors.InternalServerError,
@@ -153,7 +497,225 @@ func (s *Service) Update(ctx context.Context, id int, input map[string]interface
 	return nil
 }
 
-func (s *Service) UpdateCurrent(ctx context.Context, input map[string]interface{}) error {
+// Pause lets a member of the stand close it for a while, purchases are
+// rejected until it is resumed.
+func (s *Service) Pause(ctx context.Context, id int, input map[string]interface{}) error {
+	if err := s.checkMember(ctx, id); err != nil {
+		return err
+	}
+
+	isPaused, ok := input["is_paused"].(bool)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("is_paused is not a valid boolean"),
+		}
+	}
+
+	err := s.repository.UpdatePause(id, isPaused)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// UploadImage lets an owner replace the picture of the stand.
+func (s *Service) UploadImage(ctx context.Context, id int, file io.Reader) (models.Stand, error) {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return models.Stand{}, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+	if err := s.checkOwner(id, userId); err != nil {
+		return models.Stand{}, err
+	}
+
+	stand, err := s.repository.FindById(id)
+	if err != nil {
+		return stand, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	image, err := s.mediaService.SaveImage(fmt.Sprintf("stands/%d", id), file)
+	if err != nil {
+		return stand, err
+	}
+
+	err = s.repository.UpdateImage(id, image)
+	if err != nil {
+		s.mediaService.DeleteImage(&image.URL, &image.ThumbnailURL)
+		return stand, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	s.mediaService.DeleteImage(stand.ImageURL, stand.ThumbnailURL)
+
+	stand.ImageURL = &image.URL
+	stand.ThumbnailURL = &image.ThumbnailURL
+
+	return stand, nil
+}
+
+// SetTags lets an owner replace the tags of the stand, unknown names are
+// added as categories.
+func (s *Service) SetTags(ctx context.Context, id int, input map[string]interface{}) error {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+	if err := s.checkOwner(id, userId); err != nil {
+		return err
+	}
+
+	names, err := tag.ParseNames(input, "tags")
+	if err != nil {
+		return err
+	}
+
+	err = s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		tagRepository := s.tagRepository.WithTx(tx)
+
+		tags, err := tagRepository.FindAllByNames(names)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		tagIds := map[string]int{}
+		for _, standTag := range tags {
+			tagIds[standTag.Name] = standTag.Id
+		}
+
+		ids := []int{}
+		for _, name := range names {
+			if _, ok := tagIds[name]; !ok {
+				tagIds[name], err = tagRepository.Create(map[string]interface{}{
+					"name": name,
+					"type": models.TagTypeCategory,
+				})
+				if err != nil {
+					return errors.CustomError{
+						Key: errors.InternalServerError,
+						Err: err,
+					}
+				}
+			}
+			ids = append(ids, tagIds[name])
+		}
+
+		if err := tagRepository.ReplaceStandTags(id, ids); err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+func (s *Service) GetSchedules(ctx context.Context, id int, kermesseId int) ([]models.StandSchedule, error) {
+	schedules, err := s.repository.FindAllSchedules(id, kermesseId)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return schedules, nil
+}
+
+// CreateSchedule lets an owner add an opening slot of the stand in a
+// kermesse, opens_at and closes_at are RFC 3339 dates.
+func (s *Service) CreateSchedule(ctx context.Context, id int, input map[string]interface{}) error {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+	if err := s.checkOwner(id, userId); err != nil {
+		return err
+	}
+
+	kermesseId, err := utils.GetIntFromMap(input, "kermesse_id")
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+	hasStand, err := s.kermesseRepository.HasStand(kermesseId, id)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if !hasStand {
+		return errors.CustomError{
+			Key: errors.NotFound,
+			Err: goErrors.New("stand is not in the kermesse"),
+		}
+	}
+
+	dates := map[string]time.Time{}
+	for _, key := range []string{"opens_at", "closes_at"} {
+		value, _ := input[key].(string)
+		date, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New(key + " is not a valid date"),
+			}
+		}
+		dates[key] = date
+	}
+	if !dates["closes_at"].After(dates["opens_at"]) {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("closes_at must be after opens_at"),
+		}
+	}
+
+	err = s.repository.CreateSchedule(map[string]interface{}{
+		"stand_id":    id,
+		"kermesse_id": kermesseId,
+		"opens_at":    dates["opens_at"],
+		"closes_at":   dates["closes_at"],
+	})
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) DeleteSchedule(ctx context.Context, id int, scheduleId int) error {
 	userId, ok := ctx.Value(models.UserIDKey).(int)
 	if !ok {
 		return errors.CustomError{
@@ -161,8 +723,105 @@ func (s *Service) UpdateCurrent(ctx context.Context, input map[string]interface{
 			Err: goErrors.New("user id not found in context"),
 		}
 	}
+	if err := s.checkOwner(id, userId); err != nil {
+		return err
+	}
+
+	schedule, err := s.repository.FindScheduleById(scheduleId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if schedule.StandId != id {
+		return errors.CustomError{
+			Key: errors.NotFound,
+			Err: goErrors.New("schedule not found"),
+		}
+	}
+
+	err = s.repository.DeleteSchedule(scheduleId)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// setStock logs the change to the stock of the stand as an adjustment, stock
+// is nil when it is not changed.
+func (s *Service) setStock(tx *sqlx.Tx, id int, userId int, stock *int) error {
+	if stock == nil {
+		return nil
+	}
+
+	stand, err := s.repository.WithTx(tx).FindByIdForUpdate(id)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if *stock == stand.Stock {
+		return nil
+	}
+
+	_, err = s.stockRepository.WithTx(tx).Create(map[string]interface{}{
+		"stand_id": id,
+		"kind":     models.StockMovementKindAdjustment,
+		"quantity": *stock - stand.Stock,
+		"reason":   "stock set on the stand",
+		"user_id":  userId,
+	})
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// parseStock reads the stock given in input, nil when there is none.
+func parseStock(input map[string]interface{}) (*int, error) {
+	if input["stock"] == nil {
+		return nil, nil
+	}
+
+	stock, err := utils.GetIntFromMap(input, "stock")
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+	if stock < 0 {
+		return nil, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("stock can't be negative"),
+		}
+	}
+
+	return &stock, nil
+}
+
+// withTags sets the tags of the stands.
+func (s *Service) withTags(stands []models.Stand) error {
+	if len(stands) == 0 {
+		return nil
+	}
 
-	err := s.repository.UpdateByUserId(userId, input)
+	ids := []int{}
+	for _, stand := range stands {
+		ids = append(ids, stand.Id)
+	}
+
+	standTags, err := s.tagRepository.FindAllByStandIds(ids)
 	if err != nil {
 		return errors.CustomError{
 			Key: errors.InternalServerError,
@@ -170,5 +829,80 @@ func (s *Service) UpdateCurrent(ctx context.Context, input map[string]interface{
 		}
 	}
 
+	tags := map[int][]models.Tag{}
+	for _, standTag := range standTags {
+		tags[standTag.StandId] = append(tags[standTag.StandId], standTag.Tag)
+	}
+	for i := range stands {
+		stands[i].Tags = tags[stands[i].Id]
+		if stands[i].Tags == nil {
+			stands[i].Tags = []models.Tag{}
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) checkMember(ctx context.Context, id int) error {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	member, err := s.repository.FindMemberByUserId(userId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if member.StandId != id {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not a member of the stand"),
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) checkOwner(id int, userId int) error {
+	member, err := s.repository.FindMemberByUserId(userId)
+	if err != nil && !goErrors.Is(err, sql.ErrNoRows) {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if member.StandId != id || member.Role != models.StandMemberRoleOwner {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not an owner of the stand"),
+		}
+	}
+
+	return nil
+}
+
+// checkNoStand makes sure the user doesn't work at a stand already, a stand
+// holder is a member of a single stand.
+func checkNoStand(repository StandRepository, userId int) error {
+	_, err := repository.FindMemberByUserId(userId)
+	if err == nil {
+		return errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("user already works at a stand"),
+		}
+	}
+	if !goErrors.Is(err, sql.ErrNoRows) {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,198 @@
+package stock
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+	"standmaster/third_party/database"
+)
+
+type StockRepository interface {
+	WithTx(tx *sqlx.Tx) StockRepository
+	FindAllByStandId(standId int, filters map[string]interface{}) ([]models.StockMovement, error)
+	Report(standId int, kermesseId int) ([]models.StockReportLine, error)
+	Create(input map[string]interface{}) (*models.StockAlert, error)
+	SetThreshold(standId int, productId *int, threshold *int) error
+}
+
+type Repository struct {
+	db database.Queryer
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) WithTx(tx *sqlx.Tx) StockRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
+func (s *Repository) FindAllByStandId(standId int, filters map[string]interface{}) ([]models.StockMovement, error) {
+	movements := []models.StockMovement{}
+	query := "SELECT * FROM stock_movements WHERE stand_id=$1"
+	args := []interface{}{standId}
+	if filters["kermesse_id"] != nil {
+		args = append(args, filters["kermesse_id"])
+		query += fmt.Sprintf(" AND kermesse_id=$%d", len(args))
+	}
+	if filters["product_id"] != nil {
+		args = append(args, filters["product_id"])
+		query += fmt.Sprintf(" AND product_id=$%d", len(args))
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+	err := s.db.Select(&movements, query, args...)
+
+	return movements, err
+}
+
+// Report sums the movements of the stand during the kermesse by product, the
+// opening stock is the one before the first of them and the closing stock
+// the one after the last.
+func (s *Repository) Report(standId int, kermesseId int) ([]models.StockReportLine, error) {
+	lines := []models.StockReportLine{}
+	query := `
+		SELECT
+			m.product_id AS product_id,
+			COALESCE(p.name, s.name) AS name,
+			(ARRAY_AGG(m.stock_after - m.quantity ORDER BY m.created_at, m.id))[1] AS opening_stock,
+			COALESCE(SUM(m.quantity) FILTER (WHERE m.kind IN ('INITIAL', 'RESTOCK')), 0) AS restocked,
+			COALESCE(-SUM(m.quantity) FILTER (WHERE m.kind = 'SALE'), 0) AS sold,
+			COALESCE(SUM(m.quantity) FILTER (WHERE m.kind = 'REFUND'), 0) AS refunded,
+			COALESCE(-SUM(m.quantity) FILTER (WHERE m.kind = 'WASTE'), 0) AS wasted,
+			COALESCE(SUM(m.quantity) FILTER (WHERE m.kind = 'ADJUSTMENT'), 0) AS adjusted,
+			(ARRAY_AGG(m.stock_after ORDER BY m.created_at DESC, m.id DESC))[1] AS closing_stock
+		FROM stock_movements m
+		JOIN stands s ON m.stand_id = s.id
+		LEFT JOIN stand_products p ON m.product_id = p.id
+		WHERE m.stand_id=$1 AND m.kermesse_id=$2
+		GROUP BY m.product_id, p.name, s.name
+		ORDER BY m.product_id NULLS FIRST
+	`
+	err := s.db.Select(&lines, query, standId, kermesseId)
+
+	return lines, err
+}
+
+// Create appends a movement to the log and applies it to the stock of the
+// stand, or of its product, which is never written elsewhere. A movement
+// without kermesse_id belongs to the running kermesse of the stand, if any.
+// The alert is returned when the movement takes the stock down to its
+// low-stock threshold or out of stock, and only the first time until the
+// stock is above it again. It must run in a transaction to keep the log and
+// the stock in step.
+func (s *Repository) Create(input map[string]interface{}) (*models.StockAlert, error) {
+	var stock int
+	var before, after *string
+	var err error
+	if input["product_id"] != nil {
+		query := `
+			UPDATE stand_products p SET stock=p.stock+$1, stock_alert=` + stockAlert + `
+			FROM (SELECT stock_alert FROM stand_products WHERE id=$2) old
+			WHERE p.id=$2 AND p.stand_id=$3
+			RETURNING p.stock, old.stock_alert, p.stock_alert
+		`
+		err = s.db.QueryRow(query, input["quantity"], input["product_id"], input["stand_id"]).Scan(&stock, &before, &after)
+	} else {
+		query := `
+			UPDATE stands s SET stock=s.stock+$1, stock_alert=` + stockAlert + `
+			FROM (SELECT stock_alert FROM stands WHERE id=$2) old
+			WHERE s.id=$2
+			RETURNING s.stock, old.stock_alert, s.stock_alert
+		`
+		err = s.db.QueryRow(query, input["quantity"], input["stand_id"]).Scan(&stock, &before, &after)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var kermesseId *int
+	query := `
+		INSERT INTO stock_movements (stand_id, product_id, kermesse_id, kind, quantity, stock_after, reason, user_id, interaction_id)
+		VALUES (
+			$1, $2,
+			COALESCE($3::INTEGER, (
+				SELECT k.id
+				FROM kermesses_stands ks
+				JOIN kermesses k ON ks.kermesse_id = k.id
+				WHERE ks.stand_id = $1 AND k.status = $10
+				ORDER BY k.id DESC
+				LIMIT 1
+			)),
+			$4, $5, $6, $7, $8, $9
+		)
+		RETURNING kermesse_id
+	`
+	err = s.db.QueryRow(query, input["stand_id"], input["product_id"], input["kermesse_id"], input["kind"], input["quantity"], stock, input["reason"], input["user_id"], input["interaction_id"], models.KermesseStatusRunning).Scan(&kermesseId)
+	if err != nil {
+		return nil, err
+	}
+
+	// running low once the stock is out doesn't raise a new alert
+	if after == nil || (before != nil && (*before == *after || *after == models.StockAlertLow)) {
+		return nil, nil
+	}
+
+	alert := &models.StockAlert{
+		Kind:       *after,
+		KermesseId: kermesseId,
+		Stock:      stock,
+	}
+	alert.StandId, _ = input["stand_id"].(int)
+	if productId, ok := input["product_id"].(int); ok {
+		alert.ProductId = &productId
+	}
+
+	return alert, nil
+}
+
+// SetThreshold sets the low-stock threshold of the stand, or of its product
+// when productId is set, nil removes it. The current stock doesn't raise an
+// alert, only the next movements do.
+func (s *Repository) SetThreshold(standId int, productId *int, threshold *int) error {
+	var result sql.Result
+	var err error
+	if productId != nil {
+		query := `
+			UPDATE stand_products SET low_stock_threshold=$1::INTEGER, stock_alert=` + thresholdAlert + `
+			WHERE id=$2 AND stand_id=$3
+		`
+		result, err = s.db.Exec(query, threshold, *productId, standId)
+	} else {
+		query := `
+			UPDATE stands SET low_stock_threshold=$1::INTEGER, stock_alert=` + thresholdAlert + `
+			WHERE id=$2
+		`
+		result, err = s.db.Exec(query, threshold, standId)
+	}
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// stockAlert is the alert of a stock once $1 is added to it.
+const stockAlert = `CASE
+				WHEN stock + $1 <= 0 THEN 'OUT_OF_STOCK'::stock_alert_enum
+				WHEN stock + $1 <= low_stock_threshold THEN 'LOW_STOCK'::stock_alert_enum
+			END`
+
+// thresholdAlert is the alert of a stock once its threshold is set to $1.
+const thresholdAlert = `CASE
+				WHEN stock <= 0 THEN 'OUT_OF_STOCK'::stock_alert_enum
+				WHEN stock <= $1::INTEGER THEN 'LOW_STOCK'::stock_alert_enum
+			END`
@@ -0,0 +1,55 @@
+package tag
+
+import (
+	goErrors "errors"
+	"strings"
+
+	"standmaster/pkg/errors"
+)
+
+// ParseNames reads the list of tag names given in input[key], names are
+// trimmed, lower cased and deduplicated.
+func ParseNames(input map[string]interface{}, key string) ([]string, error) {
+	values, ok := input[key].([]interface{})
+	if !ok {
+		return nil, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New(key + " is not a valid list"),
+		}
+	}
+
+	names := []string{}
+	for _, value := range values {
+		name, ok := value.(string)
+		if !ok {
+			return nil, errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New(key + " is not a valid list"),
+			}
+		}
+		names = append(names, name)
+	}
+
+	return normalize(names), nil
+}
+
+// SplitNames reads a comma separated list of tag names, as given in query
+// parameters.
+func SplitNames(value string) []string {
+	return normalize(strings.Split(value, ","))
+}
+
+func normalize(values []string) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, value := range values {
+		name := strings.ToLower(strings.TrimSpace(value))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	return names
+}
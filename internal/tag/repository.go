@@ -0,0 +1,113 @@
+package tag
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"standmaster/internal/models"
+	"standmaster/third_party/database"
+)
+
+type TagRepository interface {
+	WithTx(tx *sqlx.Tx) TagRepository
+	FindAll() ([]models.Tag, error)
+	FindAllByNames(names []string) ([]models.Tag, error)
+	Create(input map[string]interface{}) (int, error)
+
+	FindAllByStandIds(standIds []int) ([]models.StandTag, error)
+	ReplaceStandTags(standId int, tagIds []int) error
+
+	FindAllergies(userId int) ([]models.Tag, error)
+	ReplaceAllergies(userId int, tagIds []int) error
+}
+
+type Repository struct {
+	db database.Queryer
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) WithTx(tx *sqlx.Tx) TagRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
+func (s *Repository) FindAll() ([]models.Tag, error) {
+	tags := []models.Tag{}
+	query := "SELECT * FROM tags ORDER BY type, name"
+	err := s.db.Select(&tags, query)
+
+	return tags, err
+}
+
+func (s *Repository) FindAllByNames(names []string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	query := "SELECT * FROM tags WHERE name = ANY($1) ORDER BY type, name"
+	err := s.db.Select(&tags, query, pq.Array(names))
+
+	return tags, err
+}
+
+func (s *Repository) Create(input map[string]interface{}) (int, error) {
+	var id int
+	query := "INSERT INTO tags (name, type) VALUES ($1, $2) RETURNING id"
+	err := s.db.QueryRow(query, input["name"], input["type"]).Scan(&id)
+
+	return id, err
+}
+
+func (s *Repository) FindAllByStandIds(standIds []int) ([]models.StandTag, error) {
+	tags := []models.StandTag{}
+	query := `
+		SELECT st.stand_id AS stand_id, t.id AS id, t.name AS name, t.type AS type
+		FROM stand_tags st
+		JOIN tags t ON st.tag_id = t.id
+		WHERE st.stand_id = ANY($1)
+		ORDER BY t.type, t.name
+	`
+	err := s.db.Select(&tags, query, pq.Array(standIds))
+
+	return tags, err
+}
+
+func (s *Repository) ReplaceStandTags(standId int, tagIds []int) error {
+	query := "DELETE FROM stand_tags WHERE stand_id=$1"
+	if _, err := s.db.Exec(query, standId); err != nil {
+		return err
+	}
+
+	query = "INSERT INTO stand_tags (stand_id, tag_id) SELECT $1, UNNEST($2::INTEGER[])"
+	_, err := s.db.Exec(query, standId, pq.Array(tagIds))
+
+	return err
+}
+
+func (s *Repository) FindAllergies(userId int) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	query := `
+		SELECT t.*
+		FROM user_allergies ua
+		JOIN tags t ON ua.tag_id = t.id
+		WHERE ua.user_id=$1
+		ORDER BY t.name
+	`
+	err := s.db.Select(&tags, query, userId)
+
+	return tags, err
+}
+
+func (s *Repository) ReplaceAllergies(userId int, tagIds []int) error {
+	query := "DELETE FROM user_allergies WHERE user_id=$1"
+	if _, err := s.db.Exec(query, userId); err != nil {
+		return err
+	}
+
+	query = "INSERT INTO user_allergies (user_id, tag_id) SELECT $1, UNNEST($2::INTEGER[])"
+	_, err := s.db.Exec(query, userId, pq.Array(tagIds))
+
+	return err
+}
@@ -0,0 +1,148 @@
+package tag
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+	"standmaster/internal/user"
+	"standmaster/pkg/errors"
+	"standmaster/third_party/database"
+)
+
+type TagService interface {
+	GetAll(ctx context.Context) ([]models.Tag, error)
+	GetAllergies(ctx context.Context, childId int) ([]models.Tag, error)
+	SetAllergies(ctx context.Context, childId int, input map[string]interface{}) error
+}
+
+type Service struct {
+	repository     TagRepository
+	userRepository user.UserRepository
+	transactor     database.Transactor
+}
+
+func NewService(repository TagRepository, userRepository user.UserRepository, transactor database.Transactor) *Service {
+	return &Service{
+		repository:     repository,
+		userRepository: userRepository,
+		transactor:     transactor,
+	}
+}
+
+func (s *Service) GetAll(ctx context.Context) ([]models.Tag, error) {
+	tags, err := s.repository.FindAll()
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return tags, nil
+}
+
+func (s *Service) GetAllergies(ctx context.Context, childId int) ([]models.Tag, error) {
+	if err := s.checkParent(ctx, childId); err != nil {
+		return nil, err
+	}
+
+	tags, err := s.repository.FindAllergies(childId)
+	if err != nil {
+		return nil, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return tags, nil
+}
+
+// SetAllergies replaces the allergies of the child with the allergen tags
+// given in input.
+func (s *Service) SetAllergies(ctx context.Context, childId int, input map[string]interface{}) error {
+	if err := s.checkParent(ctx, childId); err != nil {
+		return err
+	}
+
+	names, err := ParseNames(input, "allergies")
+	if err != nil {
+		return err
+	}
+
+	tags, err := s.repository.FindAllByNames(names)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	known := map[string]bool{}
+	tagIds := []int{}
+	for _, tag := range tags {
+		if tag.Type == models.TagTypeAllergen {
+			known[tag.Name] = true
+			tagIds = append(tagIds, tag.Id)
+		}
+	}
+	for _, name := range names {
+		if !known[name] {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: fmt.Errorf("%s is not an allergen", name),
+			}
+		}
+	}
+
+	err = s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		if err := s.repository.WithTx(tx).ReplaceAllergies(childId, tagIds); err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+func (s *Service) checkParent(ctx context.Context, childId int) error {
+	parentId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	child, err := s.userRepository.FindById(childId)
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if child.ParentId == nil || *child.ParentId != parentId {
+		return errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("user is not the parent of the child"),
+		}
+	}
+
+	return nil
+}
@@ -5,17 +5,20 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"standmaster/internal/models"
+	"standmaster/third_party/database"
 )
 
 type TicketRepository interface {
+	WithTx(tx *sqlx.Tx) TicketRepository
 	FindAll(filters map[string]interface{}) ([]models.Ticket, error)
 	FindById(id int) (models.Ticket, error)
-	Create(input map[string]interface{}) error
+	Create(input map[string]interface{}) (int, error)
 	CanCreate(input map[string]interface{}) (bool, error)
+	FindTombolaByIdForUpdate(id int) (models.Tombola, error)
 }
 
 type Repository struct {
-	db *sqlx.DB
+	db database.Queryer
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
@@ -24,6 +27,12 @@ func NewRepository(db *sqlx.DB) *Repository {
 	}
 }
 
+func (s *Repository) WithTx(tx *sqlx.Tx) TicketRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
 func (s *Repository) FindAll(filters map[string]interface{}) ([]models.Ticket, error) {
 	tickets := []models.Ticket{}
 	query := `
@@ -103,14 +112,25 @@ func (s *Repository) CanCreate(input map[string]interface{}) (bool, error) {
 			WHERE ku.kermesse_id = $1 AND ku.user_id = $2 AND k.status = $3
 		) AS is_associated
 	`
-	err := s.db.QueryRow(query, input["kermesse_id"], input["user_id"], models.KermesseStatusStarted).Scan(&isAssociated)
+	err := s.db.QueryRow(query, input["kermesse_id"], input["user_id"], models.KermesseStatusRunning).Scan(&isAssociated)
 
 	return isAssociated, err
 }
 
-func (s *Repository) Create(input map[string]interface{}) error {
-	query := "INSERT INTO tickets (user_id, tombola_id) VALUES ($1, $2)"
-	_, err := s.db.Exec(query, input["user_id"], input["tombola_id"])
+func (s *Repository) Create(input map[string]interface{}) (int, error) {
+	var id int
+	query := "INSERT INTO tickets (user_id, tombola_id) VALUES ($1, $2) RETURNING id"
+	err := s.db.QueryRow(query, input["user_id"], input["tombola_id"]).Scan(&id)
+
+	return id, err
+}
+
+// FindTombolaByIdForUpdate locks the tombola the ticket is sold for, so it
+// can't be drawn until the sale is done.
+func (s *Repository) FindTombolaByIdForUpdate(id int) (models.Tombola, error) {
+	tombola := models.Tombola{}
+	query := "SELECT * FROM tombolas WHERE id=$1 FOR UPDATE"
+	err := s.db.Get(&tombola, query, id)
 
-	return err
+	return tombola, err
 }
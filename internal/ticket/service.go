@@ -4,12 +4,18 @@ import (
 	"context"
 	"database/sql"
 	goErrors "errors"
+	"time"
 
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/childrule"
+	"standmaster/internal/kermesse"
+	"standmaster/internal/ledger"
 	"standmaster/internal/models"
 	"standmaster/internal/tombola"
 	"standmaster/internal/user"
 	"standmaster/pkg/errors"
 	"standmaster/pkg/utils"
+	"standmaster/third_party/database"
 )
 
 type TicketService interface {
@@ -19,16 +25,24 @@ type TicketService interface {
 }
 
 type Service struct {
-	repository        TicketRepository
-	tombolaRepository tombola.TombolaRepository
-	userRepository    user.UserRepository
+	repository          TicketRepository
+	tombolaRepository   tombola.TombolaRepository
+	kermesseRepository  kermesse.KermesseRepository
+	userRepository      user.UserRepository
+	ledgerRepository    ledger.LedgerRepository
+	childRuleRepository childrule.ChildRuleRepository
+	transactor          database.Transactor
 }
 
-func NewService(repository TicketRepository, tombolaRepository tombola.TombolaRepository, userRepository user.UserRepository) *Service {
+func NewService(repository TicketRepository, tombolaRepository tombola.TombolaRepository, kermesseRepository kermesse.KermesseRepository, userRepository user.UserRepository, ledgerRepository ledger.LedgerRepository, childRuleRepository childrule.ChildRuleRepository, transactor database.Transactor) *Service {
 	return &Service{
-		repository:        repository,
-		tombolaRepository: tombolaRepository,
-		userRepository:    userRepository,
+		repository:          repository,
+		tombolaRepository:   tombolaRepository,
+		kermesseRepository:  kermesseRepository,
+		userRepository:      userRepository,
+		ledgerRepository:    ledgerRepository,
+		childRuleRepository: childRuleRepository,
+		transactor:          transactor,
 	}
 }
 
@@ -115,6 +129,11 @@ func (s *Service) Create(ctx context.Context, input map[string]interface{}) erro
 		}
 	}
 
+	// tickets are only sold while the kermesse is open
+	if err := kermesse.CheckOpen(s.kermesseRepository, tombola.KermesseId, time.Now()); err != nil {
+		return err
+	}
+
 	userId, ok := ctx.Value(models.UserIDKey).(int)
 	if !ok {
 		return errors.CustomError{
@@ -122,63 +141,107 @@ func (s *Service) Create(ctx context.Context, input map[string]interface{}) erro
 			Err: goErrors.New("user id not found in context"),
 		}
 	}
-	user, err := s.userRepository.FindById(userId)
-	if err != nil {
-		if goErrors.Is(err, sql.ErrNoRows) {
+	err = s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+		userRepository := s.userRepository.WithTx(tx)
+		ledgerRepository := s.ledgerRepository.WithTx(tx)
+		childRuleRepository := s.childRuleRepository.WithTx(tx)
+
+		// lock the tombola first so a concurrent draw can't end it meanwhile
+		tombola, err := repository.FindTombolaByIdForUpdate(tombolaId)
+		if err != nil {
 			return errors.CustomError{
-				Key: errors.NotFound,
+				Key: errors.InternalServerError,
 				Err: err,
 			}
 		}
-		return errors.CustomError{
-			Key: errors.InternalServerError,
-			Err: err,
+		if tombola.Status != models.TombolaStatusStarted {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("tombola is not started or already finished"),
+			}
 		}
-	}
 
-	// check if user has enough credit
-	if user.Credit < tombola.Price {
-		return errors.CustomError{
-			Key: errors.BadRequest,
-			Err: goErrors.New("not enough credit"),
+		user, err := userRepository.FindByIdForUpdate(userId)
+		if err != nil {
+			if goErrors.Is(err, sql.ErrNoRows) {
+				return errors.CustomError{
+					Key: errors.NotFound,
+					Err: err,
+				}
+			}
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
 		}
-	}
 
-	// check if user belongs to the kermesse
-	canCreate, err := s.repository.CanCreate(map[string]interface{}{
-		"kermesse_id": tombola.KermesseId,
-		"user_id":     userId,
-	})
-	if err != nil {
-		return errors.CustomError{
-			Key: errors.InternalServerError,
-			Err: err,
+		// check if user has enough credit
+		if user.Credit < tombola.Price {
+			return errors.CustomError{
+				Key: errors.NotEnoughCredit,
+				Err: goErrors.New("not enough credit"),
+			}
 		}
-	}
-	if !canCreate {
-		return errors.CustomError{
-			Key: errors.Forbidden,
-			Err: goErrors.New("forbidden"),
+
+		// check if user belongs to the kermesse
+		canCreate, err := repository.CanCreate(map[string]interface{}{
+			"kermesse_id": tombola.KermesseId,
+			"user_id":     userId,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		if !canCreate {
+			return errors.CustomError{
+				Key: errors.Forbidden,
+				Err: goErrors.New("forbidden"),
+			}
 		}
-	}
 
-	// decrease user's credit
-	err = s.userRepository.UpdateCredit(userId, -tombola.Price)
-	if err != nil {
-		return errors.CustomError{
-			Key: errors.InternalServerError,
-			Err: err,
+		// check the rules the parent set for the child
+		err = childrule.Check(childRuleRepository, userId, childrule.Purchase{
+			KermesseId: tombola.KermesseId,
+			Amount:     tombola.Price,
+			At:         time.Now(),
+		})
+		if err != nil {
+			return err
 		}
-	}
 
-	input["user_id"] = userId
+		input["user_id"] = userId
 
-	err = s.repository.Create(input)
-	if err != nil {
-		return errors.CustomError{
-			Key: errors.InternalServerError,
-			Err: err,
+		ticketId, err := repository.Create(input)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
 		}
+
+		// decrease user's credit
+		if tombola.Price > 0 {
+			err = ledgerRepository.Create(map[string]interface{}{
+				"debit_user_id": userId,
+				"amount":        tombola.Price,
+				"kind":          models.CreditTransactionKindTicket,
+				"ticket_id":     ticketId,
+			})
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
 	}
 
 	return nil
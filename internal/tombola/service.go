@@ -74,7 +74,7 @@ func (s *Service) Create(ctx context.Context, input map[string]interface{}) erro
 			Err: error,
 		}
 	}
-	kermesse, err := s.kermesseRepository.FindById(kermesseId)
+	event, err := s.kermesseRepository.FindById(kermesseId)
 	if err != nil {
 		if goErrors.Is(err, sql.ErrNoRows) {
 			return errors.CustomError{
@@ -88,10 +88,10 @@ func (s *Service) Create(ctx context.Context, input map[string]interface{}) erro
 		}
 	}
 
-	if kermesse.Status == models.KermesseStatusEnded {
+	if kermesse.IsOver(event.Status) {
 		return errors.CustomError{
 			Key: errors.BadRequest,
-			Err: goErrors.New("kermesse is ended"),
+			Err: goErrors.New("kermesse is closed"),
 		}
 	}
 
@@ -102,7 +102,7 @@ func (s *Service) Create(ctx context.Context, input map[string]interface{}) erro
 			Err: goErrors.New("user id not found in context"),
 		}
 	}
-	if kermesse.UserId != userId {
+	if event.UserId != userId {
 		return errors.CustomError{
 			Key: errors.Forbidden,
 			Err: goErrors.New("forbidden"),
@@ -135,7 +135,7 @@ func (s *Service) Update(ctx context.Context, id int, input map[string]interface
 		}
 	}
 
-	kermesse, err := s.kermesseRepository.FindById(tombola.KermesseId)
+	event, err := s.kermesseRepository.FindById(tombola.KermesseId)
 	if err != nil {
 		if goErrors.Is(err, sql.ErrNoRows) {
 			return errors.CustomError{
@@ -149,10 +149,10 @@ func (s *Service) Update(ctx context.Context, id int, input map[string]interface
 		}
 	}
 
-	if kermesse.Status == models.KermesseStatusEnded {
+	if kermesse.IsOver(event.Status) {
 		return errors.CustomError{
 			Key: errors.BadRequest,
-			Err: goErrors.New("kermesse is ended"),
+			Err: goErrors.New("kermesse is closed"),
 		}
 	}
 
@@ -163,7 +163,7 @@ func (s *Service) Update(ctx context.Context, id int, input map[string]interface
 			Err: goErrors.New("user id not found in context"),
 		}
 	}
-	if kermesse.UserId != userId {
+	if event.UserId != userId {
 		return errors.CustomError{
 			Key: errors.Forbidden,
 			Err: goErrors.New("forbidden"),
@@ -196,7 +196,7 @@ func (s *Service) Finish(ctx context.Context, id int) error {
 		}
 	}
 
-	kermesse, err := s.kermesseRepository.FindById(tombola.KermesseId)
+	event, err := s.kermesseRepository.FindById(tombola.KermesseId)
 	if err != nil {
 		if goErrors.Is(err, sql.ErrNoRows) {
 			return errors.CustomError{
@@ -210,10 +210,10 @@ func (s *Service) Finish(ctx context.Context, id int) error {
 		}
 	}
 
-	if kermesse.Status == models.KermesseStatusEnded {
+	if kermesse.IsOver(event.Status) {
 		return errors.CustomError{
 			Key: errors.BadRequest,
-			Err: goErrors.New("kermesse is ended"),
+			Err: goErrors.New("kermesse is closed"),
 		}
 	}
 
@@ -224,7 +224,7 @@ func (s *Service) Finish(ctx context.Context, id int) error {
 			Err: goErrors.New("user id not found in context"),
 		}
 	}
-	if kermesse.UserId != userId {
+	if event.UserId != userId {
 		return errors.CustomError{
 			Key: errors.Forbidden,
 			Err: goErrors.New("forbidden"),
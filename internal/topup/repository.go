@@ -0,0 +1,71 @@
+package topup
+
+import (
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+	"standmaster/third_party/database"
+)
+
+type TopUpRepository interface {
+	WithTx(tx *sqlx.Tx) TopUpRepository
+	FindBySessionIdForUpdate(sessionId string) (models.TopUp, error)
+	FindByPaymentIntentIdForUpdate(paymentIntentId string) (models.TopUp, error)
+	Create(input map[string]interface{}) error
+	Update(id int, input map[string]interface{}) error
+	UpdateStatus(id int, status string) error
+}
+
+type Repository struct {
+	db database.Queryer
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) WithTx(tx *sqlx.Tx) TopUpRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
+func (s *Repository) FindBySessionIdForUpdate(sessionId string) (models.TopUp, error) {
+	topUp := models.TopUp{}
+	query := "SELECT * FROM topups WHERE session_id=$1 FOR UPDATE"
+	err := s.db.Get(&topUp, query, sessionId)
+
+	return topUp, err
+}
+
+func (s *Repository) FindByPaymentIntentIdForUpdate(paymentIntentId string) (models.TopUp, error) {
+	topUp := models.TopUp{}
+	query := "SELECT * FROM topups WHERE payment_intent_id=$1 FOR UPDATE"
+	err := s.db.Get(&topUp, query, paymentIntentId)
+
+	return topUp, err
+}
+
+func (s *Repository) Create(input map[string]interface{}) error {
+	query := "INSERT INTO topups (user_id, session_id, amount, credit) VALUES ($1, $2, $3, $4)"
+	_, err := s.db.Exec(query, input["user_id"], input["session_id"], input["amount"], input["credit"])
+
+	return err
+}
+
+// Update changes the top-up, an empty payment_intent_id is stored as NULL as
+// sessions paid without a payment intent would break its unique constraint.
+func (s *Repository) Update(id int, input map[string]interface{}) error {
+	query := "UPDATE topups SET status=$1, payment_intent_id=NULLIF($2, ''), refunded_credit=$3, updated_at=CURRENT_TIMESTAMP WHERE id=$4"
+	_, err := s.db.Exec(query, input["status"], input["payment_intent_id"], input["refunded_credit"], id)
+
+	return err
+}
+
+func (s *Repository) UpdateStatus(id int, status string) error {
+	query := "UPDATE topups SET status=$1, updated_at=CURRENT_TIMESTAMP WHERE id=$2"
+	_, err := s.db.Exec(query, status, id)
+
+	return err
+}
@@ -0,0 +1,360 @@
+package topup
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/ledger"
+	"standmaster/internal/models"
+	"standmaster/pkg/errors"
+	"standmaster/pkg/utils"
+	"standmaster/third_party/database"
+	"standmaster/third_party/payment"
+)
+
+type TopUpService interface {
+	Create(ctx context.Context, input map[string]interface{}) (models.TopUpSession, error)
+	Complete(sessionId string, paymentIntentId string) error
+	AwaitPayment(sessionId string, paymentIntentId string) error
+	Expire(sessionId string) error
+	Fail(sessionId string) error
+	Refund(paymentIntentId string, amountRefunded int) error
+	Dispute(paymentIntentId string) error
+}
+
+type Service struct {
+	repository       TopUpRepository
+	ledgerRepository ledger.LedgerRepository
+	paymentProvider  payment.Provider
+	transactor       database.Transactor
+}
+
+func NewService(repository TopUpRepository, ledgerRepository ledger.LedgerRepository, paymentProvider payment.Provider, transactor database.Transactor) *Service {
+	return &Service{
+		repository:       repository,
+		ledgerRepository: ledgerRepository,
+		paymentProvider:  paymentProvider,
+		transactor:       transactor,
+	}
+}
+
+func (s *Service) Create(ctx context.Context, input map[string]interface{}) (models.TopUpSession, error) {
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return models.TopUpSession{}, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+
+	// amount is in euros
+	amount, err := utils.GetIntFromMap(input, "amount")
+	if err != nil {
+		return models.TopUpSession{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: err,
+		}
+	}
+	if amount <= 0 {
+		return models.TopUpSession{}, errors.CustomError{
+			Key: errors.BadRequest,
+			Err: goErrors.New("amount must be positive"),
+		}
+	}
+
+	creditPerEuro, err := strconv.Atoi(os.Getenv("PAYMENT_CREDIT_PER_EURO"))
+	if err != nil {
+		return models.TopUpSession{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	credit := amount * creditPerEuro
+
+	checkout, err := s.paymentProvider.CreateCheckout(userId, amount*100, credit)
+	if err != nil {
+		return models.TopUpSession{}, errors.CustomError{
+			Key: errors.BadGateway,
+			Err: err,
+		}
+	}
+
+	err = s.repository.Create(map[string]interface{}{
+		"user_id":    userId,
+		"session_id": checkout.SessionId,
+		"amount":     amount * 100,
+		"credit":     credit,
+	})
+	if err != nil {
+		return models.TopUpSession{}, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return models.TopUpSession{
+		SessionId: checkout.SessionId,
+		URL:       checkout.URL,
+		Amount:    amount * 100,
+		Credit:    credit,
+	}, nil
+}
+
+// Complete credits the parent with the amount stored when the session was
+// created, a top-up that isn't awaiting its payment anymore is left untouched.
+func (s *Service) Complete(sessionId string, paymentIntentId string) error {
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+		ledgerRepository := s.ledgerRepository.WithTx(tx)
+
+		topUp, err := findTopUp(repository.FindBySessionIdForUpdate(sessionId))
+		if err != nil {
+			return err
+		}
+
+		if topUp.Status != models.TopUpStatusPending && topUp.Status != models.TopUpStatusProcessing {
+			return nil
+		}
+
+		err = ledgerRepository.Create(map[string]interface{}{
+			"credit_user_id":    topUp.UserId,
+			"amount":            topUp.Credit,
+			"kind":              models.CreditTransactionKindTopUp,
+			"stripe_session_id": topUp.SessionId,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		err = repository.Update(topUp.Id, map[string]interface{}{
+			"status":            models.TopUpStatusCompleted,
+			"payment_intent_id": paymentIntentId,
+			"refunded_credit":   topUp.RefundedCredit,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+// AwaitPayment marks a checkout paid with a delayed payment method, the
+// parent is credited once the payment succeeds.
+func (s *Service) AwaitPayment(sessionId string, paymentIntentId string) error {
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+
+		topUp, err := findTopUp(repository.FindBySessionIdForUpdate(sessionId))
+		if err != nil {
+			return err
+		}
+
+		if topUp.Status != models.TopUpStatusPending {
+			return nil
+		}
+
+		err = repository.Update(topUp.Id, map[string]interface{}{
+			"status":            models.TopUpStatusProcessing,
+			"payment_intent_id": paymentIntentId,
+			"refunded_credit":   topUp.RefundedCredit,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+func (s *Service) Expire(sessionId string) error {
+	return s.close(sessionId, models.TopUpStatusExpired, models.TopUpStatusPending)
+}
+
+func (s *Service) Fail(sessionId string) error {
+	return s.close(sessionId, models.TopUpStatusFailed, models.TopUpStatusPending, models.TopUpStatusProcessing)
+}
+
+// Refund claws back the credit matching the amount refunded so far by the
+// payment provider, the parent's balance may become negative.
+func (s *Service) Refund(paymentIntentId string, amountRefunded int) error {
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+		ledgerRepository := s.ledgerRepository.WithTx(tx)
+
+		topUp, err := findTopUp(repository.FindByPaymentIntentIdForUpdate(paymentIntentId))
+		if err != nil {
+			return err
+		}
+
+		if topUp.Status != models.TopUpStatusCompleted && topUp.Status != models.TopUpStatusPartiallyRefunded {
+			return nil
+		}
+
+		status := models.TopUpStatusPartiallyRefunded
+		if amountRefunded >= topUp.Amount {
+			amountRefunded = topUp.Amount
+			status = models.TopUpStatusRefunded
+		}
+
+		// the refunded amount is cumulative, only claw back what wasn't already
+		refundedCredit := topUp.Credit * amountRefunded / topUp.Amount
+		if refundedCredit > topUp.RefundedCredit {
+			err = ledgerRepository.Create(map[string]interface{}{
+				"debit_user_id":     topUp.UserId,
+				"amount":            refundedCredit - topUp.RefundedCredit,
+				"kind":              models.CreditTransactionKindTopUpRefund,
+				"stripe_session_id": topUp.SessionId,
+			})
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+		}
+
+		err = repository.Update(topUp.Id, map[string]interface{}{
+			"status":            status,
+			"payment_intent_id": topUp.PaymentIntentId,
+			"refunded_credit":   max(refundedCredit, topUp.RefundedCredit),
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+// Dispute claws back all the credit of the top-up not refunded yet.
+func (s *Service) Dispute(paymentIntentId string) error {
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+		ledgerRepository := s.ledgerRepository.WithTx(tx)
+
+		topUp, err := findTopUp(repository.FindByPaymentIntentIdForUpdate(paymentIntentId))
+		if err != nil {
+			return err
+		}
+
+		if topUp.Status != models.TopUpStatusCompleted && topUp.Status != models.TopUpStatusPartiallyRefunded {
+			return nil
+		}
+
+		if topUp.Credit > topUp.RefundedCredit {
+			err = ledgerRepository.Create(map[string]interface{}{
+				"debit_user_id":     topUp.UserId,
+				"amount":            topUp.Credit - topUp.RefundedCredit,
+				"kind":              models.CreditTransactionKindTopUpDispute,
+				"stripe_session_id": topUp.SessionId,
+			})
+			if err != nil {
+				return errors.CustomError{
+					Key: errors.InternalServerError,
+					Err: err,
+				}
+			}
+		}
+
+		err = repository.Update(topUp.Id, map[string]interface{}{
+			"status":            models.TopUpStatusDisputed,
+			"payment_intent_id": topUp.PaymentIntentId,
+			"refunded_credit":   topUp.Credit,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+// close moves a top-up that never got paid to status when it still is in
+// one of the from statuses.
+func (s *Service) close(sessionId string, status string, from ...string) error {
+	err := s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+
+		topUp, err := findTopUp(repository.FindBySessionIdForUpdate(sessionId))
+		if err != nil {
+			return err
+		}
+
+		if !slices.Contains(from, topUp.Status) {
+			return nil
+		}
+
+		err = repository.UpdateStatus(topUp.Id, status)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
+	}
+
+	return nil
+}
+
+func findTopUp(topUp models.TopUp, err error) (models.TopUp, error) {
+	if err != nil {
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return topUp, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return topUp, errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return topUp, nil
+}
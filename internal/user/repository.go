@@ -5,21 +5,23 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"standmaster/internal/models"
+	"standmaster/third_party/database"
 )
 
 type UserRepository interface {
+	WithTx(tx *sqlx.Tx) UserRepository
 	FindAll(filters map[string]interface{}) ([]models.UserBasic, error)
 	FindAllChildren(id int, filters map[string]interface{}) ([]models.UserBasic, error)
 	FindById(id int) (models.User, error)
+	FindByIdForUpdate(id int) (models.User, error)
 	FindByEmail(email string) (models.User, error)
 	Create(input map[string]interface{}) error
 	Update(id int, input map[string]interface{}) error
-	UpdateCredit(id int, amount int) error
 	HasStand(id int) (bool, error)
 }
 
 type Repository struct {
-	db *sqlx.DB
+	db database.Queryer
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
@@ -28,6 +30,12 @@ func NewRepository(db *sqlx.DB) *Repository {
 	}
 }
 
+func (s *Repository) WithTx(tx *sqlx.Tx) UserRepository {
+	return &Repository{
+		db: tx,
+	}
+}
+
 func (s *Repository) FindAll(filters map[string]interface{}) ([]models.UserBasic, error) {
 	users := []models.UserBasic{}
 	query := `
@@ -78,6 +86,14 @@ func (s *Repository) FindById(id int) (models.User, error) {
 	return user, err
 }
 
+func (s *Repository) FindByIdForUpdate(id int) (models.User, error) {
+	user := models.User{}
+	query := "SELECT * FROM users WHERE id=$1 FOR UPDATE"
+	err := s.db.Get(&user, query, id)
+
+	return user, err
+}
+
 func (s *Repository) FindByEmail(email string) (models.User, error) {
 	user := models.User{}
 	query := "SELECT * FROM users WHERE email=$1"
@@ -100,16 +116,9 @@ func (s *Repository) Update(id int, input map[string]interface{}) error {
 	return err
 }
 
-func (s *Repository) UpdateCredit(id int, amount int) error {
-	query := "UPDATE users SET credit=credit+$1 WHERE id=$2"
-	_, err := s.db.Exec(query, amount, id)
-
-	return err
-}
-
 func (s *Repository) HasStand(id int) (bool, error) {
 	var count int
-	query := "SELECT COUNT(*) FROM stands WHERE user_id=$1"
+	query := "SELECT COUNT(*) FROM stand_members WHERE user_id=$1"
 	err := s.db.Get(&count, query, id)
 
 	return count >= 1, err
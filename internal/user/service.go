@@ -8,12 +8,15 @@ import (
 	"strconv"
 
 	goJwt "github.com/golang-jwt/jwt/v5"
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/ledger"
 	"standmaster/internal/models"
 	"standmaster/pkg/errors"
 	"standmaster/pkg/generator"
 	"standmaster/pkg/hasher"
 	"standmaster/pkg/jwt"
 	"standmaster/pkg/utils"
+	"standmaster/third_party/database"
 	"standmaster/third_party/resend"
 )
 
@@ -22,9 +25,10 @@ type UserService interface {
 	GetAllChildren(ctx context.Context, params map[string]interface{}) ([]models.UserBasic, error)
 	Get(ctx context.Context, id int) (models.UserBasic, error)
 	Update(ctx context.Context, id int, input map[string]interface{}) error
-	UpdateCredit(userId, credit int) error
+	GetTransactions(ctx context.Context, id int) ([]models.CreditTransaction, error)
 	Invite(ctx context.Context, input map[string]interface{}) error
 	Pay(ctx context.Context, input map[string]interface{}) error
+	Transfer(parentId int, childId int, amount int) error
 
 	SignUp(ctx context.Context, input map[string]interface{}) error
 	SignIn(ctx context.Context, input map[string]interface{}) (models.UserMe, error)
@@ -32,14 +36,18 @@ type UserService interface {
 }
 
 type Service struct {
-	repository    UserRepository
-	resendService resend.ResendService
+	repository       UserRepository
+	ledgerRepository ledger.LedgerRepository
+	resendService    resend.ResendService
+	transactor       database.Transactor
 }
 
-func NewService(repository UserRepository, resendService resend.ResendService) *Service {
+func NewService(repository UserRepository, ledgerRepository ledger.LedgerRepository, resendService resend.ResendService, transactor database.Transactor) *Service {
 	return &Service{
-		repository:    repository,
-		resendService: resendService,
+		repository:       repository,
+		ledgerRepository: ledgerRepository,
+		resendService:    resendService,
+		transactor:       transactor,
 	}
 }
 
@@ -218,46 +226,37 @@ func (s *Service) Invite(ctx context.Context, input map[string]interface{}) erro
 	return nil
 }
 
-func (s *Service) UpdateCredit(userId, credit int) error {
-	user, err := s.repository.FindById(userId)
+func (s *Service) Pay(ctx context.Context, input map[string]interface{}) error {
+	childId, err := utils.GetIntFromMap(input, "child_id")
 	if err != nil {
-		if goErrors.Is(err, sql.ErrNoRows) {
-			return errors.CustomError{
-				Key: errors.NotFound,
-				Err: err,
-			}
-		}
 		return errors.CustomError{
-			Key: errors.InternalServerError,
+			Key: errors.BadRequest,
 			Err: err,
 		}
 	}
-	if user.Role != models.UserRoleParent {
+
+	parentId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
 		return errors.CustomError{
-			Key: errors.Forbidden,
-			Err: goErrors.New("forbidden"),
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
 		}
 	}
 
-	err = s.repository.UpdateCredit(userId, credit)
+	amount, err := utils.GetIntFromMap(input, "amount")
 	if err != nil {
 		return errors.CustomError{
-			Key: errors.InternalServerError,
+			Key: errors.BadRequest,
 			Err: err,
 		}
 	}
 
-	return nil
+	return s.Transfer(parentId, childId, amount)
 }
 
-func (s *Service) Pay(ctx context.Context, input map[string]interface{}) error {
-	childId, err := utils.GetIntFromMap(input, "child_id")
-	if err != nil {
-		return errors.CustomError{
-			Key: errors.BadRequest,
-			Err: err,
-		}
-	}
+// Transfer moves amount credits from the parent to their child, allowances
+// paid by the scheduler go through it as well.
+func (s *Service) Transfer(parentId int, childId int, amount int) error {
 	child, err := s.repository.FindById(childId)
 	if err != nil {
 		if goErrors.Is(err, sql.ErrNoRows) {
@@ -272,65 +271,105 @@ func (s *Service) Pay(ctx context.Context, input map[string]interface{}) error {
 		}
 	}
 
-	parentId, ok := ctx.Value(models.UserIDKey).(int)
-	if !ok {
+	if child.ParentId == nil || *child.ParentId != parentId {
 		return errors.CustomError{
-			Key: errors.Unauthorized,
-			Err: goErrors.New("user id not found in context"),
+			Key: errors.Forbidden,
+			Err: goErrors.New("forbidden"),
 		}
 	}
-	parent, err := s.repository.FindById(parentId)
-	if err != nil {
-		if goErrors.Is(err, sql.ErrNoRows) {
-			return errors.CustomError{
-				Key: errors.NotFound,
-				Err: err,
-			}
-		}
+
+	if amount <= 0 {
 		return errors.CustomError{
 			Key: errors.BadRequest,
-			Err: goErrors.New("parent not found"),
+			Err: goErrors.New("amount must be positive"),
 		}
 	}
 
-	if child.ParentId == nil || *child.ParentId != parent.Id {
-		return errors.CustomError{
-			Key: errors.Forbidden,
-			Err: goErrors.New("forbidden"),
+	err = s.transactor.Transaction(func(tx *sqlx.Tx) error {
+		repository := s.repository.WithTx(tx)
+		ledgerRepository := s.ledgerRepository.WithTx(tx)
+
+		parent, err := repository.FindByIdForUpdate(parentId)
+		if err != nil {
+			if goErrors.Is(err, sql.ErrNoRows) {
+				return errors.CustomError{
+					Key: errors.NotFound,
+					Err: err,
+				}
+			}
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("parent not found"),
+			}
 		}
-	}
 
-	amount, error := utils.GetIntFromMap(input, "amount")
-	if error != nil {
-		return errors.CustomError{
-			Key: errors.BadRequest,
-			Err: error,
+		if parent.Credit < amount {
+			return errors.CustomError{
+				Key: errors.BadRequest,
+				Err: goErrors.New("insufficient credit"),
+			}
 		}
-	}
-	if parent.Credit < amount {
-		return errors.CustomError{
-			Key: errors.BadRequest,
-			Err: goErrors.New("insufficient credit"),
+
+		err = ledgerRepository.Create(map[string]interface{}{
+			"debit_user_id":  parentId,
+			"credit_user_id": childId,
+			"amount":         amount,
+			"kind":           models.CreditTransactionKindTransfer,
+		})
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.FromError(err)
 	}
 
-	err = s.repository.UpdateCredit(childId, amount)
+	return nil
+}
+
+func (s *Service) GetTransactions(ctx context.Context, id int) ([]models.CreditTransaction, error) {
+	user, err := s.repository.FindById(id)
 	if err != nil {
-		return errors.CustomError{
+		if goErrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.CustomError{
+				Key: errors.NotFound,
+				Err: err,
+			}
+		}
+		return nil, errors.CustomError{
 			Key: errors.InternalServerError,
 			Err: err,
 		}
 	}
 
-	err = s.repository.UpdateCredit(parentId, -amount)
+	userId, ok := ctx.Value(models.UserIDKey).(int)
+	if !ok {
+		return nil, errors.CustomError{
+			Key: errors.Unauthorized,
+			Err: goErrors.New("user id not found in context"),
+		}
+	}
+	if user.Id != userId && (user.ParentId == nil || *user.ParentId != userId) {
+		return nil, errors.CustomError{
+			Key: errors.Forbidden,
+			Err: goErrors.New("forbidden"),
+		}
+	}
+
+	transactions, err := s.ledgerRepository.FindAllByUserId(id)
 	if err != nil {
-		return errors.CustomError{
+		return nil, errors.CustomError{
 			Key: errors.InternalServerError,
 			Err: err,
 		}
 	}
 
-	return nil
+	return transactions, nil
 }
 
 func (s *Service) SignUp(ctx context.Context, input map[string]interface{}) error {
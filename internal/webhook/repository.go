@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"github.com/jmoiron/sqlx"
+	"standmaster/internal/models"
+)
+
+type WebhookRepository interface {
+	FindByEventId(eventId string) (models.WebhookEvent, error)
+	IsSessionProcessed(eventType string, sessionId string) (bool, error)
+	Create(input map[string]interface{}) error
+	UpdateStatus(id int, status string, message *string) error
+}
+
+type Repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (s *Repository) FindByEventId(eventId string) (models.WebhookEvent, error) {
+	event := models.WebhookEvent{}
+	query := "SELECT * FROM webhook_events WHERE event_id=$1"
+	err := s.db.Get(&event, query, eventId)
+
+	return event, err
+}
+
+func (s *Repository) IsSessionProcessed(eventType string, sessionId string) (bool, error) {
+	var isProcessed bool
+	query := "SELECT EXISTS ( SELECT 1 FROM webhook_events WHERE type=$1 AND session_id=$2 AND status=$3 ) AS is_processed"
+	err := s.db.QueryRow(query, eventType, sessionId, models.WebhookEventStatusProcessed).Scan(&isProcessed)
+
+	return isProcessed, err
+}
+
+// Create stores a received event, a new delivery of an event already stored
+// is left as is.
+func (s *Repository) Create(input map[string]interface{}) error {
+	query := `
+		INSERT INTO webhook_events (event_id, type, session_id, payload)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (event_id) DO NOTHING
+	`
+	_, err := s.db.Exec(query, input["event_id"], input["type"], input["session_id"], input["payload"])
+
+	return err
+}
+
+func (s *Repository) UpdateStatus(id int, status string, message *string) error {
+	query := "UPDATE webhook_events SET status=$1, error=$2, processed_at=CURRENT_TIMESTAMP WHERE id=$3"
+	_, err := s.db.Exec(query, status, message, id)
+
+	return err
+}
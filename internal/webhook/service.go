@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	goErrors "errors"
+	"log"
+
+	"standmaster/internal/models"
+	"standmaster/internal/topup"
+	"standmaster/pkg/errors"
+	"standmaster/third_party/payment"
+)
+
+type WebhookService interface {
+	Handle(event payment.Event, payload []byte) error
+}
+
+type Service struct {
+	repository   WebhookRepository
+	topUpService topup.TopUpService
+}
+
+func NewService(repository WebhookRepository, topUpService topup.TopUpService) *Service {
+	return &Service{
+		repository:   repository,
+		topUpService: topUpService,
+	}
+}
+
+// Handle processes an event at most once: deliveries of an event, or of
+// another event of the same type for the same checkout session, that was
+// already handled have no side effect.
+func (s *Service) Handle(event payment.Event, payload []byte) error {
+	var sessionId *string
+	if event.SessionId != "" {
+		sessionId = &event.SessionId
+	}
+
+	err := s.repository.Create(map[string]interface{}{
+		"event_id":   event.Id,
+		"type":       event.Type,
+		"session_id": sessionId,
+		"payload":    string(payload),
+	})
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	stored, err := s.repository.FindByEventId(event.Id)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+	if stored.Status == models.WebhookEventStatusProcessed || stored.Status == models.WebhookEventStatusIgnored {
+		return nil
+	}
+
+	if sessionId != nil {
+		isProcessed, err := s.repository.IsSessionProcessed(event.Type, *sessionId)
+		if err != nil {
+			return errors.CustomError{
+				Key: errors.InternalServerError,
+				Err: err,
+			}
+		}
+		if isProcessed {
+			message := "session already processed"
+			return s.updateStatus(stored.Id, models.WebhookEventStatusIgnored, &message)
+		}
+	}
+
+	status, err := s.process(event)
+	if err != nil {
+		message := err.Error()
+		if err := s.updateStatus(stored.Id, models.WebhookEventStatusFailed, &message); err != nil {
+			log.Printf("Error saving webhook event %s outcome: %v\n", event.Id, err)
+		}
+		return errors.FromError(err)
+	}
+
+	return s.updateStatus(stored.Id, status, nil)
+}
+
+func (s *Service) process(event payment.Event) (string, error) {
+	var err error
+	switch event.Type {
+	case payment.EventCheckoutCompleted:
+		err = s.topUpService.Complete(event.SessionId, event.PaymentIntentId)
+	case payment.EventCheckoutPending:
+		err = s.topUpService.AwaitPayment(event.SessionId, event.PaymentIntentId)
+	case payment.EventCheckoutExpired:
+		err = s.topUpService.Expire(event.SessionId)
+	case payment.EventCheckoutFailed:
+		err = s.topUpService.Fail(event.SessionId)
+	case payment.EventChargeRefunded:
+		err = s.topUpService.Refund(event.PaymentIntentId, event.AmountRefunded)
+	case payment.EventChargeDisputed:
+		err = s.topUpService.Dispute(event.PaymentIntentId)
+	default:
+		log.Printf("Unhandled event type: %s\n", event.Type)
+		return models.WebhookEventStatusIgnored, nil
+	}
+
+	if err != nil {
+		var customError errors.CustomError
+		if goErrors.As(err, &customError) && customError.Key == errors.NotFound {
+			log.Printf("No top-up for event %s\n", event.Id)
+			return models.WebhookEventStatusIgnored, nil
+		}
+		return "", err
+	}
+
+	return models.WebhookEventStatusProcessed, nil
+}
+
+func (s *Service) updateStatus(id int, status string, message *string) error {
+	err := s.repository.UpdateStatus(id, status, message)
+	if err != nil {
+		return errors.CustomError{
+			Key: errors.InternalServerError,
+			Err: err,
+		}
+	}
+
+	return nil
+}
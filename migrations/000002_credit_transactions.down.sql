@@ -0,0 +1,7 @@
+-- Drop tables
+DROP TRIGGER IF EXISTS "credit_transactions_append_only" ON "credit_transactions";
+DROP FUNCTION IF EXISTS credit_transactions_append_only;
+DROP TABLE IF EXISTS "credit_transactions";
+
+-- Drop custom models
+DROP TYPE IF EXISTS credit_transactions_kind_enum;
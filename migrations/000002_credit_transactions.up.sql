@@ -0,0 +1,35 @@
+--- Table: credit_transactions
+
+CREATE TYPE credit_transactions_kind_enum AS ENUM ('OPENING_BALANCE', 'TOPUP', 'TRANSFER', 'INTERACTION', 'TICKET');
+
+-- A NULL account is the outside of the platform (payment provider, tombola pot...).
+CREATE TABLE "credit_transactions" (
+  "id" SERIAL PRIMARY KEY,
+  "debit_user_id" INTEGER REFERENCES "users"("id") DEFAULT NULL,
+  "credit_user_id" INTEGER REFERENCES "users"("id") DEFAULT NULL,
+  "amount" INTEGER NOT NULL CHECK ("amount" > 0),
+  "kind" credit_transactions_kind_enum NOT NULL,
+  "interaction_id" INTEGER REFERENCES "interactions"("id") DEFAULT NULL,
+  "ticket_id" INTEGER REFERENCES "tickets"("id") DEFAULT NULL,
+  "stripe_session_id" VARCHAR(255) DEFAULT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  CHECK ("debit_user_id" IS NOT NULL OR "credit_user_id" IS NOT NULL)
+);
+
+CREATE INDEX "credit_transactions_debit_user_id_idx" ON "credit_transactions"("debit_user_id");
+CREATE INDEX "credit_transactions_credit_user_id_idx" ON "credit_transactions"("credit_user_id");
+
+-- Entries are append-only, a mistake is fixed by posting a new entry.
+CREATE FUNCTION credit_transactions_append_only() RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'credit_transactions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "credit_transactions_append_only"
+BEFORE UPDATE OR DELETE ON "credit_transactions"
+FOR EACH ROW EXECUTE FUNCTION credit_transactions_append_only();
+
+-- Open the ledger with the balances users already have.
+INSERT INTO "credit_transactions" ("credit_user_id", "amount", "kind")
+SELECT "id", "credit", 'OPENING_BALANCE' FROM "users" WHERE "credit" > 0;
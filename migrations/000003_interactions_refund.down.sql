@@ -0,0 +1,15 @@
+ALTER TABLE "interactions"
+  DROP COLUMN IF EXISTS "refunded_quantity",
+  DROP COLUMN IF EXISTS "quantity";
+
+-- Enum values can't be dropped, recreate the type without REFUNDED.
+UPDATE "interactions" SET "status" = 'ENDED' WHERE "status" = 'REFUNDED';
+ALTER TYPE interactions_status_enum RENAME TO interactions_status_enum_old;
+CREATE TYPE interactions_status_enum AS ENUM ('STARTED', 'ENDED');
+ALTER TABLE "interactions"
+  ALTER COLUMN "status" DROP DEFAULT,
+  ALTER COLUMN "status" TYPE interactions_status_enum USING "status"::text::interactions_status_enum,
+  ALTER COLUMN "status" SET DEFAULT 'STARTED';
+DROP TYPE interactions_status_enum_old;
+
+-- REFUND stays in credit_transactions_kind_enum, the ledger is append-only.
@@ -0,0 +1,12 @@
+ALTER TYPE interactions_status_enum ADD VALUE 'REFUNDED';
+ALTER TYPE credit_transactions_kind_enum ADD VALUE 'REFUND';
+
+ALTER TABLE "interactions"
+  ADD COLUMN "quantity" INTEGER NOT NULL DEFAULT 1 CHECK ("quantity" > 0),
+  ADD COLUMN "refunded_quantity" INTEGER NOT NULL DEFAULT 0 CHECK ("refunded_quantity" >= 0);
+
+-- Recover the quantity of past consumptions from their price.
+UPDATE "interactions" i
+SET "quantity" = GREATEST(i."credit" / s."price", 1)
+FROM "stands" s
+WHERE i."stand_id" = s."id" AND i."type" = 'CONSUMPTION' AND s."price" > 0;
@@ -0,0 +1,5 @@
+-- Drop tables
+DROP TABLE IF EXISTS "topups";
+
+-- Drop custom models
+DROP TYPE IF EXISTS topups_status_enum;
@@ -0,0 +1,14 @@
+--- Table: topups
+
+CREATE TYPE topups_status_enum AS ENUM ('PENDING', 'COMPLETED');
+
+CREATE TABLE "topups" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" INTEGER NOT NULL REFERENCES "users"("id"),
+  "session_id" VARCHAR(255) UNIQUE NOT NULL,
+  "amount" INTEGER NOT NULL CHECK ("amount" > 0),
+  "credit" INTEGER NOT NULL CHECK ("credit" > 0),
+  "status" topups_status_enum NOT NULL DEFAULT 'PENDING',
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,5 @@
+-- Drop tables
+DROP TABLE IF EXISTS "webhook_events";
+
+-- Drop custom models
+DROP TYPE IF EXISTS webhook_events_status_enum;
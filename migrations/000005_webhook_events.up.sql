@@ -0,0 +1,17 @@
+--- Table: webhook_events
+
+CREATE TYPE webhook_events_status_enum AS ENUM ('RECEIVED', 'PROCESSED', 'IGNORED', 'FAILED');
+
+CREATE TABLE "webhook_events" (
+  "id" SERIAL PRIMARY KEY,
+  "event_id" VARCHAR(255) UNIQUE NOT NULL,
+  "type" VARCHAR(255) NOT NULL,
+  "session_id" VARCHAR(255) DEFAULT NULL,
+  "payload" JSONB NOT NULL,
+  "status" webhook_events_status_enum NOT NULL DEFAULT 'RECEIVED',
+  "error" TEXT DEFAULT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  "processed_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE INDEX "webhook_events_session_id_idx" ON "webhook_events"("session_id");
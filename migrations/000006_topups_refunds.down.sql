@@ -0,0 +1,16 @@
+ALTER TABLE "topups"
+  DROP COLUMN IF EXISTS "refunded_credit",
+  DROP COLUMN IF EXISTS "payment_intent_id";
+
+-- Enum values can't be dropped, recreate the type with the settled states folded back.
+ALTER TYPE topups_status_enum RENAME TO topups_status_enum_old;
+CREATE TYPE topups_status_enum AS ENUM ('PENDING', 'COMPLETED');
+ALTER TABLE "topups"
+  ALTER COLUMN "status" DROP DEFAULT,
+  ALTER COLUMN "status" TYPE topups_status_enum USING (
+    CASE WHEN "status"::text IN ('COMPLETED', 'PARTIALLY_REFUNDED', 'REFUNDED', 'DISPUTED') THEN 'COMPLETED' ELSE 'PENDING' END
+  )::topups_status_enum,
+  ALTER COLUMN "status" SET DEFAULT 'PENDING';
+DROP TYPE topups_status_enum_old;
+
+-- TOPUP_REFUND and TOPUP_DISPUTE stay in credit_transactions_kind_enum, the ledger is append-only.
@@ -0,0 +1,13 @@
+ALTER TYPE topups_status_enum ADD VALUE 'PROCESSING';
+ALTER TYPE topups_status_enum ADD VALUE 'EXPIRED';
+ALTER TYPE topups_status_enum ADD VALUE 'FAILED';
+ALTER TYPE topups_status_enum ADD VALUE 'PARTIALLY_REFUNDED';
+ALTER TYPE topups_status_enum ADD VALUE 'REFUNDED';
+ALTER TYPE topups_status_enum ADD VALUE 'DISPUTED';
+
+ALTER TYPE credit_transactions_kind_enum ADD VALUE 'TOPUP_REFUND';
+ALTER TYPE credit_transactions_kind_enum ADD VALUE 'TOPUP_DISPUTE';
+
+ALTER TABLE "topups"
+  ADD COLUMN "payment_intent_id" VARCHAR(255) UNIQUE DEFAULT NULL,
+  ADD COLUMN "refunded_credit" INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- CASH_TOPUP stays in credit_transactions_kind_enum, the ledger is append-only.
+ALTER TABLE "credit_transactions"
+  DROP COLUMN IF EXISTS "cash_topup_id";
+
+-- Drop tables
+DROP TABLE IF EXISTS "cash_topups";
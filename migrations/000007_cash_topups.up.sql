@@ -0,0 +1,20 @@
+--- Table: cash_topups
+
+ALTER TYPE credit_transactions_kind_enum ADD VALUE 'CASH_TOPUP';
+
+CREATE TABLE "cash_topups" (
+  "id" SERIAL PRIMARY KEY,
+  "kermesse_id" INTEGER NOT NULL REFERENCES "kermesses"("id"),
+  "user_id" INTEGER NOT NULL REFERENCES "users"("id"),
+  "operator_id" INTEGER NOT NULL REFERENCES "users"("id"),
+  "amount" INTEGER NOT NULL CHECK ("amount" > 0),
+  "credit" INTEGER NOT NULL CHECK ("credit" > 0),
+  "receipt_number" VARCHAR(255) NOT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE ("kermesse_id", "receipt_number")
+);
+
+CREATE INDEX "cash_topups_kermesse_id_created_at_idx" ON "cash_topups"("kermesse_id", "created_at");
+
+ALTER TABLE "credit_transactions"
+  ADD COLUMN "cash_topup_id" INTEGER REFERENCES "cash_topups"("id") DEFAULT NULL;
@@ -0,0 +1,5 @@
+-- Drop tables
+DROP TABLE IF EXISTS "child_rules";
+
+-- Drop custom models
+DROP TYPE IF EXISTS child_rules_type_enum;
@@ -0,0 +1,21 @@
+--- Table: child_rules
+
+CREATE TYPE child_rules_type_enum AS ENUM ('DAILY_LIMIT', 'KERMESSE_LIMIT', 'MAX_PURCHASE', 'BLOCKED_STAND_TYPE', 'BLOCKED_STAND');
+
+-- Limits use value, blocked stand types stand_type and blocked stands stand_id.
+CREATE TABLE "child_rules" (
+  "id" SERIAL PRIMARY KEY,
+  "child_id" INTEGER NOT NULL REFERENCES "users"("id"),
+  "type" child_rules_type_enum NOT NULL,
+  "value" INTEGER DEFAULT NULL CHECK ("value" >= 0),
+  "stand_type" stands_type_enum DEFAULT NULL,
+  "stand_id" INTEGER REFERENCES "stands"("id") DEFAULT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  CHECK (
+    ("type" IN ('DAILY_LIMIT', 'KERMESSE_LIMIT', 'MAX_PURCHASE') AND "value" IS NOT NULL) OR
+    ("type" = 'BLOCKED_STAND_TYPE' AND "stand_type" IS NOT NULL) OR
+    ("type" = 'BLOCKED_STAND' AND "stand_id" IS NOT NULL)
+  )
+);
+
+CREATE INDEX "child_rules_child_id_idx" ON "child_rules"("child_id");
@@ -0,0 +1,35 @@
+-- Enum values can't be dropped, HOLD and HOLD_RELEASE stay in credit_transactions_kind_enum as the ledger is append-only.
+-- The holds still pending are given back to the children, the stock they
+-- reserved isn't put back. Interactions that never went through end up
+-- REFUNDED as the previous statuses have nothing closer.
+INSERT INTO "credit_transactions" ("credit_user_id", "amount", "kind", "interaction_id")
+SELECT "user_id", "credit", 'HOLD_RELEASE', "id"
+FROM "interactions"
+WHERE "status"::text = 'PENDING_APPROVAL' AND "credit" > 0;
+
+UPDATE "users" u SET "credit" = u."credit" + i."total"
+FROM (
+  SELECT "user_id", SUM("credit") AS "total"
+  FROM "interactions"
+  WHERE "status"::text = 'PENDING_APPROVAL'
+  GROUP BY "user_id"
+) i
+WHERE u."id" = i."user_id";
+
+UPDATE "interactions" SET "status" = 'REFUNDED', "refunded_quantity" = "quantity"
+WHERE "status"::text IN ('PENDING_APPROVAL', 'REJECTED', 'EXPIRED');
+
+DROP INDEX IF EXISTS "interactions_expires_at_idx";
+
+ALTER TABLE "interactions"
+  DROP COLUMN IF EXISTS "expires_at";
+
+DELETE FROM "child_rules" WHERE "type"::text = 'APPROVAL_THRESHOLD';
+
+ALTER TABLE "child_rules"
+  DROP CONSTRAINT "child_rules_check",
+  ADD CONSTRAINT "child_rules_check" CHECK (
+    ("type"::text IN ('DAILY_LIMIT', 'KERMESSE_LIMIT', 'MAX_PURCHASE') AND "value" IS NOT NULL) OR
+    ("type"::text = 'BLOCKED_STAND_TYPE' AND "stand_type" IS NOT NULL) OR
+    ("type"::text = 'BLOCKED_STAND' AND "stand_id" IS NOT NULL)
+  );
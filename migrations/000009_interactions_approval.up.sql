@@ -0,0 +1,23 @@
+ALTER TYPE child_rules_type_enum ADD VALUE 'APPROVAL_THRESHOLD';
+
+ALTER TYPE interactions_status_enum ADD VALUE 'PENDING_APPROVAL';
+ALTER TYPE interactions_status_enum ADD VALUE 'REJECTED';
+ALTER TYPE interactions_status_enum ADD VALUE 'EXPIRED';
+
+ALTER TYPE credit_transactions_kind_enum ADD VALUE 'HOLD';
+ALTER TYPE credit_transactions_kind_enum ADD VALUE 'HOLD_RELEASE';
+
+-- New enum values can't be used before the migration commits, compare as text.
+ALTER TABLE "child_rules"
+  DROP CONSTRAINT "child_rules_check",
+  ADD CONSTRAINT "child_rules_check" CHECK (
+    ("type"::text IN ('DAILY_LIMIT', 'KERMESSE_LIMIT', 'MAX_PURCHASE', 'APPROVAL_THRESHOLD') AND "value" IS NOT NULL) OR
+    ("type"::text = 'BLOCKED_STAND_TYPE' AND "stand_type" IS NOT NULL) OR
+    ("type"::text = 'BLOCKED_STAND' AND "stand_id" IS NOT NULL)
+  );
+
+-- Deadline of a PENDING_APPROVAL interaction, its hold is released after it.
+ALTER TABLE "interactions"
+  ADD COLUMN "expires_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+CREATE INDEX "interactions_expires_at_idx" ON "interactions"("expires_at");
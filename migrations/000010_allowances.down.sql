@@ -0,0 +1,7 @@
+-- Drop tables
+DROP TABLE IF EXISTS "allowance_runs";
+DROP TABLE IF EXISTS "allowances";
+
+-- Drop custom models
+DROP TYPE IF EXISTS allowance_runs_status_enum;
+DROP TYPE IF EXISTS allowances_type_enum;
@@ -0,0 +1,36 @@
+--- Table: allowances
+
+CREATE TYPE allowances_type_enum AS ENUM ('INTERVAL', 'KERMESSE_START');
+
+-- INTERVAL allowances are paid every interval_minutes while the kermesse is
+-- started, KERMESSE_START ones once when it starts.
+CREATE TABLE "allowances" (
+  "id" SERIAL PRIMARY KEY,
+  "parent_id" INTEGER NOT NULL REFERENCES "users"("id"),
+  "child_id" INTEGER NOT NULL REFERENCES "users"("id"),
+  "kermesse_id" INTEGER NOT NULL REFERENCES "kermesses"("id"),
+  "type" allowances_type_enum NOT NULL,
+  "amount" INTEGER NOT NULL CHECK ("amount" > 0),
+  "interval_minutes" INTEGER DEFAULT NULL CHECK ("interval_minutes" > 0),
+  "is_active" BOOLEAN NOT NULL DEFAULT TRUE,
+  "next_run_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  CHECK ("type" = 'KERMESSE_START' OR "interval_minutes" IS NOT NULL)
+);
+
+CREATE INDEX "allowances_next_run_at_idx" ON "allowances"("next_run_at") WHERE "is_active";
+
+--- Table: allowance_runs
+
+CREATE TYPE allowance_runs_status_enum AS ENUM ('SUCCEEDED', 'FAILED');
+
+CREATE TABLE "allowance_runs" (
+  "id" SERIAL PRIMARY KEY,
+  "allowance_id" INTEGER NOT NULL REFERENCES "allowances"("id") ON DELETE CASCADE,
+  "amount" INTEGER NOT NULL,
+  "status" allowance_runs_status_enum NOT NULL,
+  "error" TEXT DEFAULT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "allowance_runs_allowance_id_idx" ON "allowance_runs"("allowance_id");
@@ -0,0 +1,3 @@
+-- Drop tables
+DROP TABLE IF EXISTS "interaction_items";
+DROP TABLE IF EXISTS "stand_products";
@@ -0,0 +1,27 @@
+--- Table: stand_products
+
+CREATE TABLE "stand_products" (
+  "id" SERIAL PRIMARY KEY,
+  "stand_id" INTEGER NOT NULL REFERENCES "stands"("id"),
+  "name" VARCHAR(255) NOT NULL,
+  "price" INTEGER NOT NULL DEFAULT 0 CHECK ("price" >= 0),
+  "stock" INTEGER NOT NULL DEFAULT 0 CHECK ("stock" >= 0),
+  "is_active" BOOLEAN NOT NULL DEFAULT TRUE,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "stand_products_stand_id_idx" ON "stand_products"("stand_id");
+
+--- Table: interaction_items
+
+-- Name and price are copied from the product at the time of the purchase.
+CREATE TABLE "interaction_items" (
+  "id" SERIAL PRIMARY KEY,
+  "interaction_id" INTEGER NOT NULL REFERENCES "interactions"("id"),
+  "product_id" INTEGER NOT NULL REFERENCES "stand_products"("id"),
+  "name" VARCHAR(255) NOT NULL,
+  "price" INTEGER NOT NULL CHECK ("price" >= 0),
+  "quantity" INTEGER NOT NULL CHECK ("quantity" > 0)
+);
+
+CREATE INDEX "interaction_items_interaction_id_idx" ON "interaction_items"("interaction_id");
@@ -0,0 +1,8 @@
+-- Restore constraints
+ALTER TABLE "stands" ADD CONSTRAINT "stands_user_id_key" UNIQUE ("user_id");
+
+-- Drop tables
+DROP TABLE IF EXISTS "stand_members";
+
+-- Drop custom models
+DROP TYPE IF EXISTS stand_members_role_enum;
@@ -0,0 +1,20 @@
+--- Table: stand_members
+
+CREATE TYPE stand_members_role_enum AS ENUM ('OWNER', 'CASHIER');
+
+-- A stand holder works for at most one stand, stands.user_id stays the owner
+-- whose wallet receives the revenue of the stand.
+CREATE TABLE "stand_members" (
+  "id" SERIAL PRIMARY KEY,
+  "stand_id" INTEGER NOT NULL REFERENCES "stands"("id"),
+  "user_id" INTEGER NOT NULL UNIQUE REFERENCES "users"("id"),
+  "role" stand_members_role_enum NOT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "stand_members_stand_id_idx" ON "stand_members"("stand_id");
+
+INSERT INTO "stand_members" ("stand_id", "user_id", "role")
+SELECT "id", "user_id", 'OWNER' FROM "stands";
+
+ALTER TABLE "stands" DROP CONSTRAINT IF EXISTS "stands_user_id_key";
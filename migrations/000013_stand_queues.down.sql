@@ -0,0 +1,8 @@
+-- Drop tables
+DROP TABLE IF EXISTS "queue_entries";
+
+-- Drop custom models
+DROP TYPE IF EXISTS queue_entries_status_enum;
+
+ALTER TABLE "interactions"
+  DROP COLUMN IF EXISTS "ended_at";
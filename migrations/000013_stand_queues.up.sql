@@ -0,0 +1,24 @@
+--- Table: interactions
+
+ALTER TABLE "interactions" ADD COLUMN "ended_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+--- Table: queue_entries
+
+CREATE TYPE queue_entries_status_enum AS ENUM ('WAITING', 'CALLED', 'NO_SHOW', 'LEFT', 'FAILED');
+
+-- A child waits at most once at a time in the queue of an activity stand,
+-- interaction_id is set when a stand member calls the child.
+CREATE TABLE "queue_entries" (
+  "id" SERIAL PRIMARY KEY,
+  "stand_id" INTEGER NOT NULL REFERENCES "stands"("id"),
+  "kermesse_id" INTEGER NOT NULL REFERENCES "kermesses"("id"),
+  "user_id" INTEGER NOT NULL REFERENCES "users"("id"),
+  "created_by" INTEGER NOT NULL REFERENCES "users"("id"),
+  "status" queue_entries_status_enum NOT NULL DEFAULT 'WAITING',
+  "interaction_id" INTEGER REFERENCES "interactions"("id") DEFAULT NULL,
+  "error" TEXT DEFAULT NULL,
+  "called_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX "queue_entries_waiting_idx" ON "queue_entries"("stand_id", "user_id") WHERE "status" = 'WAITING';
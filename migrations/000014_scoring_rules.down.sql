@@ -0,0 +1,6 @@
+-- Drop tables
+DROP TABLE IF EXISTS "scoring_tiers";
+DROP TABLE IF EXISTS "scoring_rules";
+
+ALTER TABLE "interactions"
+  DROP COLUMN IF EXISTS "tier";
@@ -0,0 +1,29 @@
+--- Table: interactions
+
+ALTER TABLE "interactions" ADD COLUMN "tier" VARCHAR(255) DEFAULT NULL;
+
+--- Table: scoring_rules
+
+-- The points an activity stand can award in a kermesse, participation_bonus
+-- is added to every awarded score.
+CREATE TABLE "scoring_rules" (
+  "id" SERIAL PRIMARY KEY,
+  "kermesse_id" INTEGER NOT NULL REFERENCES "kermesses"("id"),
+  "stand_id" INTEGER NOT NULL REFERENCES "stands"("id"),
+  "min_points" INTEGER NOT NULL DEFAULT 0 CHECK ("min_points" >= 0),
+  "max_points" INTEGER NOT NULL CHECK ("max_points" >= "min_points"),
+  "participation_bonus" INTEGER NOT NULL DEFAULT 0 CHECK ("participation_bonus" >= 0),
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE ("kermesse_id", "stand_id")
+);
+
+--- Table: scoring_tiers
+
+-- When a rule has tiers the stand awards one of them instead of free points.
+CREATE TABLE "scoring_tiers" (
+  "id" SERIAL PRIMARY KEY,
+  "scoring_rule_id" INTEGER NOT NULL REFERENCES "scoring_rules"("id") ON DELETE CASCADE,
+  "name" VARCHAR(255) NOT NULL,
+  "points" INTEGER NOT NULL CHECK ("points" >= 0),
+  UNIQUE ("scoring_rule_id", "name")
+);
@@ -0,0 +1,5 @@
+-- Drop tables
+DROP TABLE IF EXISTS "stand_schedules";
+
+ALTER TABLE "stands"
+  DROP COLUMN IF EXISTS "is_paused";
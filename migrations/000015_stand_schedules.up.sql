@@ -0,0 +1,19 @@
+--- Table: stands
+
+ALTER TABLE "stands" ADD COLUMN "is_paused" BOOLEAN NOT NULL DEFAULT FALSE;
+
+--- Table: stand_schedules
+
+-- Opening slots of a stand in a kermesse, a stand without slots is open as
+-- long as the kermesse is started and the stand isn't paused.
+CREATE TABLE "stand_schedules" (
+  "id" SERIAL PRIMARY KEY,
+  "stand_id" INTEGER NOT NULL REFERENCES "stands"("id"),
+  "kermesse_id" INTEGER NOT NULL REFERENCES "kermesses"("id"),
+  "opens_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+  "closes_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  CHECK ("closes_at" > "opens_at")
+);
+
+CREATE INDEX "stand_schedules_stand_id_kermesse_id_idx" ON "stand_schedules"("stand_id", "kermesse_id");
@@ -0,0 +1,7 @@
+ALTER TABLE "kermesses"
+  DROP COLUMN IF EXISTS "thumbnail_url",
+  DROP COLUMN IF EXISTS "image_url";
+
+ALTER TABLE "stands"
+  DROP COLUMN IF EXISTS "thumbnail_url",
+  DROP COLUMN IF EXISTS "image_url";
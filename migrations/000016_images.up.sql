@@ -0,0 +1,11 @@
+--- Table: stands
+
+ALTER TABLE "stands"
+  ADD COLUMN "image_url" VARCHAR(1024) DEFAULT NULL,
+  ADD COLUMN "thumbnail_url" VARCHAR(1024) DEFAULT NULL;
+
+--- Table: kermesses
+
+ALTER TABLE "kermesses"
+  ADD COLUMN "image_url" VARCHAR(1024) DEFAULT NULL,
+  ADD COLUMN "thumbnail_url" VARCHAR(1024) DEFAULT NULL;
@@ -0,0 +1,7 @@
+-- Drop tables
+DROP TABLE IF EXISTS "user_allergies";
+DROP TABLE IF EXISTS "stand_tags";
+DROP TABLE IF EXISTS "tags";
+
+-- Drop custom models
+DROP TYPE IF EXISTS tags_type_enum;
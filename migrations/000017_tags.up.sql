@@ -0,0 +1,43 @@
+--- Table: tags
+
+CREATE TYPE tags_type_enum AS ENUM ('ALLERGEN', 'DIETARY', 'CATEGORY');
+
+-- Allergens and dietary labels are fixed, categories are added by the stand
+-- owners.
+CREATE TABLE "tags" (
+  "id" SERIAL PRIMARY KEY,
+  "name" VARCHAR(255) NOT NULL UNIQUE,
+  "type" tags_type_enum NOT NULL
+);
+
+INSERT INTO "tags" ("name", "type") VALUES
+  ('gluten', 'ALLERGEN'),
+  ('nuts', 'ALLERGEN'),
+  ('peanuts', 'ALLERGEN'),
+  ('dairy', 'ALLERGEN'),
+  ('eggs', 'ALLERGEN'),
+  ('fish', 'ALLERGEN'),
+  ('shellfish', 'ALLERGEN'),
+  ('soy', 'ALLERGEN'),
+  ('sesame', 'ALLERGEN'),
+  ('vegetarian', 'DIETARY'),
+  ('vegan', 'DIETARY'),
+  ('halal', 'DIETARY'),
+  ('kosher', 'DIETARY'),
+  ('gluten-free', 'DIETARY');
+
+--- Table: stand_tags
+
+CREATE TABLE "stand_tags" (
+  "stand_id" INTEGER NOT NULL REFERENCES "stands"("id"),
+  "tag_id" INTEGER NOT NULL REFERENCES "tags"("id"),
+  PRIMARY KEY ("stand_id", "tag_id")
+);
+
+--- Table: user_allergies
+
+CREATE TABLE "user_allergies" (
+  "user_id" INTEGER NOT NULL REFERENCES "users"("id"),
+  "tag_id" INTEGER NOT NULL REFERENCES "tags"("id"),
+  PRIMARY KEY ("user_id", "tag_id")
+);
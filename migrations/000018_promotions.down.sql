@@ -0,0 +1,10 @@
+ALTER TABLE "interactions"
+  DROP COLUMN IF EXISTS "promotion_quantity",
+  DROP COLUMN IF EXISTS "discount",
+  DROP COLUMN IF EXISTS "promotion_id";
+
+-- Drop tables
+DROP TABLE IF EXISTS "promotions";
+
+-- Drop custom models
+DROP TYPE IF EXISTS promotions_type_enum;
@@ -0,0 +1,31 @@
+--- Table: promotions
+
+CREATE TYPE promotions_type_enum AS ENUM ('PERCENTAGE', 'FIXED');
+
+-- A discount on every unit sold by the stand, or on a single product when
+-- product_id is set, between starts_at and ends_at. quantity_limit caps the
+-- number of discounted units.
+CREATE TABLE "promotions" (
+  "id" SERIAL PRIMARY KEY,
+  "stand_id" INTEGER NOT NULL REFERENCES "stands"("id"),
+  "product_id" INTEGER REFERENCES "stand_products"("id") DEFAULT NULL,
+  "name" VARCHAR(255) NOT NULL,
+  "type" promotions_type_enum NOT NULL,
+  "value" INTEGER NOT NULL CHECK ("value" > 0),
+  "starts_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+  "ends_at" TIMESTAMP WITH TIME ZONE NOT NULL CHECK ("ends_at" > "starts_at"),
+  "quantity_limit" INTEGER DEFAULT NULL CHECK ("quantity_limit" > 0),
+  "used_quantity" INTEGER NOT NULL DEFAULT 0,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "promotions_stand_id_idx" ON "promotions"("stand_id");
+
+--- Table: interactions
+
+-- promotion_quantity is the number of units discounted by the promotion,
+-- given back to it when the purchase is undone.
+ALTER TABLE "interactions"
+  ADD COLUMN "promotion_id" INTEGER REFERENCES "promotions"("id") DEFAULT NULL,
+  ADD COLUMN "discount" INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN "promotion_quantity" INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+--- Table: pay_tokens
+
+-- Short lived tokens a child shows as a QR code, a stand member charges the
+-- child with it once.
+CREATE TABLE "pay_tokens" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" INTEGER NOT NULL REFERENCES "users"("id"),
+  "token_id" VARCHAR(64) NOT NULL UNIQUE,
+  "expires_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+  "used_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+  "used_by" INTEGER REFERENCES "users"("id") DEFAULT NULL,
+  "interaction_id" INTEGER REFERENCES "interactions"("id") DEFAULT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "pay_tokens_user_id_idx" ON "pay_tokens"("user_id");
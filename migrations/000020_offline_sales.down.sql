@@ -0,0 +1,5 @@
+-- Drop tables
+DROP TABLE IF EXISTS "offline_sales";
+
+-- Drop custom models
+DROP TYPE IF EXISTS offline_sales_status_enum;
@@ -0,0 +1,25 @@
+--- Table: offline_sales
+
+CREATE TYPE offline_sales_status_enum AS ENUM ('PENDING', 'ACCEPTED', 'REJECTED');
+
+-- Sales a stand device recorded offline and synced later, the idempotency key
+-- is generated by the device so a sale is only replayed once. Rejected sales
+-- are reviewed by the organizer.
+CREATE TABLE "offline_sales" (
+  "id" SERIAL PRIMARY KEY,
+  "stand_id" INTEGER NOT NULL REFERENCES "stands"("id"),
+  "kermesse_id" INTEGER NOT NULL REFERENCES "kermesses"("id"),
+  "user_id" INTEGER NOT NULL REFERENCES "users"("id"),
+  "synced_by" INTEGER NOT NULL REFERENCES "users"("id"),
+  "idempotency_key" VARCHAR(255) NOT NULL,
+  "status" offline_sales_status_enum NOT NULL DEFAULT 'PENDING',
+  "reason" VARCHAR(255) DEFAULT NULL,
+  "error" TEXT DEFAULT NULL,
+  "interaction_id" INTEGER REFERENCES "interactions"("id") DEFAULT NULL,
+  "is_resolved" BOOLEAN NOT NULL DEFAULT FALSE,
+  "sold_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE ("stand_id", "idempotency_key")
+);
+
+CREATE INDEX "offline_sales_kermesse_id_idx" ON "offline_sales"("kermesse_id");
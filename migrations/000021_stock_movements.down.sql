@@ -0,0 +1,5 @@
+-- Drop tables
+DROP TABLE IF EXISTS "stock_movements";
+
+-- Drop custom models
+DROP TYPE IF EXISTS stock_movements_kind_enum;
@@ -0,0 +1,29 @@
+--- Table: stock_movements
+
+CREATE TYPE stock_movements_kind_enum AS ENUM ('INITIAL', 'RESTOCK', 'SALE', 'REFUND', 'ADJUSTMENT', 'WASTE');
+
+-- Every change of the stock of a stand, or of one of its products when
+-- product_id is set. quantity is signed and stock_after is the stock once the
+-- movement is applied.
+CREATE TABLE "stock_movements" (
+  "id" SERIAL PRIMARY KEY,
+  "stand_id" INTEGER NOT NULL REFERENCES "stands"("id"),
+  "product_id" INTEGER REFERENCES "stand_products"("id") DEFAULT NULL,
+  "kermesse_id" INTEGER REFERENCES "kermesses"("id") DEFAULT NULL,
+  "kind" stock_movements_kind_enum NOT NULL,
+  "quantity" INTEGER NOT NULL,
+  "stock_after" INTEGER NOT NULL,
+  "reason" VARCHAR(255) NOT NULL,
+  "user_id" INTEGER REFERENCES "users"("id") DEFAULT NULL,
+  "interaction_id" INTEGER REFERENCES "interactions"("id") DEFAULT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "stock_movements_stand_id_idx" ON "stock_movements"("stand_id");
+
+-- the stock held before the log is the initial one
+INSERT INTO "stock_movements" ("stand_id", "kind", "quantity", "stock_after", "reason", "user_id")
+SELECT "id", 'INITIAL', "stock", "stock", 'stock before the movement log', "user_id" FROM "stands" WHERE "stock" <> 0;
+
+INSERT INTO "stock_movements" ("stand_id", "product_id", "kind", "quantity", "stock_after", "reason")
+SELECT "stand_id", "id", 'INITIAL', "stock", "stock", 'stock before the movement log' FROM "stand_products" WHERE "stock" <> 0;
@@ -0,0 +1,14 @@
+-- Drop tables
+DROP TABLE IF EXISTS "notifications";
+
+ALTER TABLE "stand_products"
+  DROP COLUMN IF EXISTS "stock_alert",
+  DROP COLUMN IF EXISTS "low_stock_threshold";
+
+ALTER TABLE "stands"
+  DROP COLUMN IF EXISTS "stock_alert",
+  DROP COLUMN IF EXISTS "low_stock_threshold";
+
+-- Drop custom models
+DROP TYPE IF EXISTS notifications_kind_enum;
+DROP TYPE IF EXISTS stock_alert_enum;
@@ -0,0 +1,30 @@
+CREATE TYPE stock_alert_enum AS ENUM ('LOW_STOCK', 'OUT_OF_STOCK');
+
+-- stock_alert is the last alert raised for the stock, it is cleared once the
+-- stock is above the threshold again so an alert fires once per crossing.
+ALTER TABLE "stands"
+  ADD COLUMN "low_stock_threshold" INTEGER DEFAULT NULL CHECK ("low_stock_threshold" >= 0),
+  ADD COLUMN "stock_alert" stock_alert_enum DEFAULT NULL;
+
+ALTER TABLE "stand_products"
+  ADD COLUMN "low_stock_threshold" INTEGER DEFAULT NULL CHECK ("low_stock_threshold" >= 0),
+  ADD COLUMN "stock_alert" stock_alert_enum DEFAULT NULL;
+
+--- Table: notifications
+
+CREATE TYPE notifications_kind_enum AS ENUM ('LOW_STOCK', 'OUT_OF_STOCK');
+
+-- In-app notifications, the same ones can also be sent by email.
+CREATE TABLE "notifications" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" INTEGER NOT NULL REFERENCES "users"("id"),
+  "kind" notifications_kind_enum NOT NULL,
+  "title" VARCHAR(255) NOT NULL,
+  "message" TEXT NOT NULL,
+  "stand_id" INTEGER REFERENCES "stands"("id") DEFAULT NULL,
+  "kermesse_id" INTEGER REFERENCES "kermesses"("id") DEFAULT NULL,
+  "is_read" BOOLEAN NOT NULL DEFAULT FALSE,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "notifications_user_id_idx" ON "notifications"("user_id");
@@ -0,0 +1,22 @@
+ALTER TABLE "kermesses"
+  DROP CONSTRAINT IF EXISTS "kermesses_schedule_check",
+  DROP COLUMN IF EXISTS "end_blocked_at",
+  DROP COLUMN IF EXISTS "ends_at",
+  DROP COLUMN IF EXISTS "starts_at";
+
+-- Enum values can't be dropped, recreate the types without the new ones.
+UPDATE "kermesses" SET "status" = 'STARTED' WHERE "status" = 'PLANNED';
+ALTER TYPE kermesses_status_enum RENAME TO kermesses_status_enum_old;
+CREATE TYPE kermesses_status_enum AS ENUM ('STARTED', 'ENDED');
+ALTER TABLE "kermesses"
+  ALTER COLUMN "status" DROP DEFAULT,
+  ALTER COLUMN "status" TYPE kermesses_status_enum USING "status"::text::kermesses_status_enum,
+  ALTER COLUMN "status" SET DEFAULT 'STARTED';
+DROP TYPE kermesses_status_enum_old;
+
+DELETE FROM "notifications" WHERE "kind" = 'KERMESSE_END_BLOCKED';
+ALTER TYPE notifications_kind_enum RENAME TO notifications_kind_enum_old;
+CREATE TYPE notifications_kind_enum AS ENUM ('LOW_STOCK', 'OUT_OF_STOCK');
+ALTER TABLE "notifications"
+  ALTER COLUMN "kind" TYPE notifications_kind_enum USING "kind"::text::notifications_kind_enum;
+DROP TYPE notifications_kind_enum_old;
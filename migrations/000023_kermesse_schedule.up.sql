@@ -0,0 +1,11 @@
+ALTER TYPE kermesses_status_enum ADD VALUE 'PLANNED';
+ALTER TYPE notifications_kind_enum ADD VALUE 'KERMESSE_END_BLOCKED';
+
+-- A kermesse with a schedule is opened and closed by the scheduler,
+-- end_blocked_at is set once the organizer is told that an open tombola
+-- keeps it from being closed.
+ALTER TABLE "kermesses"
+  ADD COLUMN "starts_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+  ADD COLUMN "ends_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+  ADD COLUMN "end_blocked_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+  ADD CONSTRAINT "kermesses_schedule_check" CHECK ("ends_at" > "starts_at");
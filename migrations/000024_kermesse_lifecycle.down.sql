@@ -0,0 +1,13 @@
+-- Drop tables
+DROP TABLE IF EXISTS "kermesse_status_changes";
+
+-- Enum values can't be dropped, recreate the type with the lifecycle states folded back.
+ALTER TYPE kermesses_status_enum RENAME TO kermesses_status_enum_old;
+CREATE TYPE kermesses_status_enum AS ENUM ('STARTED', 'ENDED', 'PLANNED');
+ALTER TABLE "kermesses"
+  ALTER COLUMN "status" DROP DEFAULT,
+  ALTER COLUMN "status" TYPE kermesses_status_enum USING (
+    CASE "status"::text WHEN 'RUNNING' THEN 'STARTED' WHEN 'CLOSED' THEN 'ENDED' WHEN 'ARCHIVED' THEN 'ENDED' ELSE 'PLANNED' END
+  )::kermesses_status_enum,
+  ALTER COLUMN "status" SET DEFAULT 'STARTED';
+DROP TYPE kermesses_status_enum_old;
@@ -0,0 +1,30 @@
+-- The kermesses go through the lifecycle states, PLANNED, STARTED and ENDED
+-- have no place in it so the type is recreated.
+ALTER TYPE kermesses_status_enum RENAME TO kermesses_status_enum_old;
+CREATE TYPE kermesses_status_enum AS ENUM ('DRAFT', 'PUBLISHED', 'RUNNING', 'CLOSED', 'ARCHIVED');
+ALTER TABLE "kermesses"
+  ALTER COLUMN "status" DROP DEFAULT,
+  ALTER COLUMN "status" TYPE kermesses_status_enum USING (
+    CASE "status"::text WHEN 'PLANNED' THEN 'PUBLISHED' WHEN 'STARTED' THEN 'RUNNING' ELSE 'CLOSED' END
+  )::kermesses_status_enum,
+  ALTER COLUMN "status" SET DEFAULT 'DRAFT';
+DROP TYPE kermesses_status_enum_old;
+
+--- Table: kermesse_status_changes
+
+-- Every state change of a kermesse, user_id is nil for the changes made by
+-- the scheduler.
+CREATE TABLE "kermesse_status_changes" (
+  "id" SERIAL PRIMARY KEY,
+  "kermesse_id" INTEGER NOT NULL REFERENCES "kermesses"("id"),
+  "from_status" kermesses_status_enum DEFAULT NULL,
+  "to_status" kermesses_status_enum NOT NULL,
+  "user_id" INTEGER REFERENCES "users"("id") DEFAULT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "kermesse_status_changes_kermesse_id_idx" ON "kermesse_status_changes"("kermesse_id");
+
+-- the current state of the existing kermesses starts their history
+INSERT INTO "kermesse_status_changes" ("kermesse_id", "to_status")
+SELECT "id", "status" FROM "kermesses";
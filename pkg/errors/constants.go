@@ -19,4 +19,16 @@ const (
 	InvalidCredentials = "INVALID_CREDENTIALS"
 	InvalidCode        = "INVALID_CODE"
 	ExpiredCode        = "EXPIRED_CODE"
+
+	SpendingLimitExceeded = "SPENDING_LIMIT_EXCEEDED"
+	PurchaseLimitExceeded = "PURCHASE_LIMIT_EXCEEDED"
+	StandBlocked          = "STAND_BLOCKED"
+	StandClosed           = "STAND_CLOSED"
+	KermesseClosed        = "KERMESSE_CLOSED"
+	NotEnoughCredit       = "NOT_ENOUGH_CREDIT"
+	NotEnoughStock        = "NOT_ENOUGH_STOCK"
+
+	InvalidScore    = "INVALID_SCORE"
+	FileTooLarge    = "FILE_TOO_LARGE"
+	InvalidPayToken = "INVALID_PAY_TOKEN"
 )
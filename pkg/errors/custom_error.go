@@ -1,6 +1,9 @@
 package errors
 
-import "net/http"
+import (
+	goErrors "errors"
+	"net/http"
+)
 
 type CustomError struct {
 	Key string
@@ -13,14 +16,14 @@ func (ce CustomError) Error() string {
 
 func (ce CustomError) StatusCode() int {
 	switch ce.Key {
-	case BadRequest:
+	case BadRequest, InvalidScore, InvalidPayToken, NotEnoughCredit, NotEnoughStock:
 		return http.StatusBadRequest
 	case Unauthorized:
 	case InvalidCredentials:
 	case InvalidCode:
 	case ExpiredCode:
 		return http.StatusUnauthorized
-	case Forbidden:
+	case Forbidden, SpendingLimitExceeded, PurchaseLimitExceeded, StandBlocked, StandClosed, KermesseClosed:
 		return http.StatusForbidden
 	case NotFound:
 		return http.StatusNotFound
@@ -31,6 +34,8 @@ func (ce CustomError) StatusCode() int {
 		return http.StatusConflict
 	case UnsupportedMediaType:
 		return http.StatusUnsupportedMediaType
+	case FileTooLarge:
+		return http.StatusRequestEntityTooLarge
 	case TooManyRequests:
 		return http.StatusTooManyRequests
 	case NotImplemented:
@@ -48,3 +53,17 @@ func (ce CustomError) StatusCode() int {
 
 	return http.StatusInternalServerError
 }
+
+// FromError returns err as is when it already is a CustomError and wraps it
+// as an internal server error otherwise.
+func FromError(err error) CustomError {
+	var customError CustomError
+	if goErrors.As(err, &customError) {
+		return customError
+	}
+
+	return CustomError{
+		Key: InternalServerError,
+		Err: err,
+	}
+}
@@ -0,0 +1,123 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// MaxPixels bounds the dimensions of decoded images, a small file can
+// declare a huge image.
+const MaxPixels = 40_000_000
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrTooManyPixels   = errors.New("image dimensions are too large")
+)
+
+// Sniff returns the content type of data from its first bytes, only JPEG,
+// PNG and GIF images are accepted.
+func Sniff(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+		return contentType, nil
+	}
+
+	return "", ErrUnsupportedType
+}
+
+// Thumbnail decodes the image and scales it down to fit in a size x size
+// square, keeping its ratio. JPEG images stay JPEG, others become PNG to keep
+// their transparency.
+func Thumbnail(data []byte, size int) ([]byte, string, error) {
+	contentType, err := Sniff(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, "", ErrTooManyPixels
+	}
+
+	var src image.Image
+	switch contentType {
+	case "image/jpeg":
+		src, err = jpeg.Decode(bytes.NewReader(data))
+	case "image/png":
+		src, err = png.Decode(bytes.NewReader(data))
+	case "image/gif":
+		src, err = gif.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	thumbnail := scale(src, size)
+
+	var buffer bytes.Buffer
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&buffer, thumbnail, &jpeg.Options{Quality: 80})
+	} else {
+		contentType = "image/png"
+		err = png.Encode(&buffer, thumbnail)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return buffer.Bytes(), contentType, nil
+}
+
+// scale averages the source pixels covered by each pixel of the result,
+// images already smaller than size are only copied.
+func scale(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, max(1, height*size/bounds.Dx())
+		} else {
+			width, height = max(1, width*size/bounds.Dy()), size
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pixel := color.NRGBA64Model.Convert(src.At(sx, sy)).(color.NRGBA64)
+					r += uint64(pixel.R)
+					g += uint64(pixel.G)
+					b += uint64(pixel.B)
+					a += uint64(pixel.A)
+					n++
+				}
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return dst
+}
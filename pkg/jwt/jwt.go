@@ -45,10 +45,64 @@ func GetTokenUserId(tokenString, secret string) (int, error) {
 	}
 
 	claims := token.Claims.(jwt.MapClaims)
-	userId, err := strconv.Atoi(claims["userId"].(string))
+	value, ok := claims["userId"].(string)
+	if !ok {
+		return -1, fmt.Errorf("invalid token")
+	}
+	userId, err := strconv.Atoi(value)
 	if err != nil {
 		return -1, err
 	}
 
 	return userId, nil
 }
+
+// CreatePayToken signs a payment token of the user valid until expiresAt,
+// tokenId identifies it so it can only be used once.
+func CreatePayToken(secret string, expiresAt time.Time, userId int, tokenId string) (string, error) {
+	claims := jwt.MapClaims{
+		"payUserId": strconv.Itoa(userId),
+		"jti":       tokenId,
+		"exp":       expiresAt.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(secret))
+	if err != nil {
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// GetPayToken returns the user and the id of a payment token, expired tokens
+// are invalid.
+func GetPayToken(tokenString, secret string) (int, string, error) {
+	token, err := Validate(tokenString, secret)
+	if err != nil {
+		return -1, "", err
+	}
+
+	if !token.Valid {
+		return -1, "", fmt.Errorf("invalid token")
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	value, ok := claims["payUserId"].(string)
+	if !ok {
+		return -1, "", fmt.Errorf("invalid token")
+	}
+	tokenId, ok := claims["jti"].(string)
+	if !ok || tokenId == "" {
+		return -1, "", fmt.Errorf("invalid token")
+	}
+	if claims["exp"] == nil {
+		return -1, "", fmt.Errorf("invalid token")
+	}
+	userId, err := strconv.Atoi(value)
+	if err != nil {
+		return -1, "", err
+	}
+
+	return userId, tokenId, nil
+}
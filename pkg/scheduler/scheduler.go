@@ -0,0 +1,25 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Every runs job every interval until ctx is done, errors are logged and the
+// job is run again at the next tick.
+func Every(ctx context.Context, name string, interval time.Duration, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job(); err != nil {
+				log.Printf("Error running %s: %v\n", name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Queryer is satisfied by both *sqlx.DB and *sqlx.Tx, repositories use it so
+// the same statements can run inside or outside a transaction.
+type Queryer interface {
+	Get(dest interface{}, query string, args ...interface{}) error
+	Select(dest interface{}, query string, args ...interface{}) error
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+type Transactor interface {
+	Transaction(fn func(tx *sqlx.Tx) error) error
+}
+
+type SqlxTransactor struct {
+	db *sqlx.DB
+}
+
+func NewTransactor(db *sqlx.DB) *SqlxTransactor {
+	return &SqlxTransactor{
+		db: db,
+	}
+}
+
+// Transaction runs fn in a transaction, committed when fn returns nil and
+// rolled back otherwise.
+func (t *SqlxTransactor) Transaction(fn func(tx *sqlx.Tx) error) (err error) {
+	tx, err := t.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	return fn(tx)
+}
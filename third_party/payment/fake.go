@@ -0,0 +1,113 @@
+package payment
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+const fakeSignatureHeader = "Fake-Signature"
+
+// Fake is an offline provider for development and tests: checkouts are paid
+// by calling the complete payment endpoint, which delivers a signed event to
+// the webhook.
+type Fake struct {
+	secret []byte
+}
+
+func NewFakeProvider() *Fake {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+
+	return &Fake{
+		secret: secret,
+	}
+}
+
+func (t *Fake) CreateCheckout(userId int, amount int, credit int) (Checkout, error) {
+	id, err := randomId()
+	if err != nil {
+		return Checkout{}, err
+	}
+	sessionId := "fake_cs_" + id
+
+	return Checkout{
+		SessionId: sessionId,
+		URL:       fmt.Sprintf("/payment/fake/%s/complete", sessionId),
+	}, nil
+}
+
+func (t *Fake) VerifyWebhook(payload []byte, header http.Header) error {
+	signature, err := hex.DecodeString(header.Get(fakeSignatureHeader))
+	if err != nil || !hmac.Equal(signature, t.sign(payload)) {
+		return errors.New("invalid signature")
+	}
+
+	return nil
+}
+
+func (t *Fake) ParseEvent(payload []byte) (Event, error) {
+	var event Event
+	err := json.Unmarshal(payload, &event)
+
+	return event, err
+}
+
+// CompletePayment pays the checkout session of the route and delivers the
+// event to webhook as the provider would.
+func (t *Fake) CompletePayment(webhook http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionId := mux.Vars(r)["session_id"]
+
+		id, err := randomId()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		payload, err := json.Marshal(Event{
+			Id:              "fake_evt_" + id,
+			Type:            EventCheckoutCompleted,
+			SessionId:       sessionId,
+			PaymentIntentId: "fake_pi_" + sessionId,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		request, err := http.NewRequestWithContext(r.Context(), http.MethodPost, "/webhook", bytes.NewReader(payload))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		request.Header.Set(fakeSignatureHeader, hex.EncodeToString(t.sign(payload)))
+
+		webhook.ServeHTTP(w, request)
+	}
+}
+
+func (t *Fake) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
+
+func randomId() (string, error) {
+	buffer := make([]byte, 12)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buffer), nil
+}
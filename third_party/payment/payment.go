@@ -0,0 +1,34 @@
+package payment
+
+import "net/http"
+
+// Event types every provider maps its own events to.
+const (
+	EventCheckoutCompleted string = "checkout.completed"
+	EventCheckoutPending   string = "checkout.pending"
+	EventCheckoutExpired   string = "checkout.expired"
+	EventCheckoutFailed    string = "checkout.failed"
+	EventChargeRefunded    string = "charge.refunded"
+	EventChargeDisputed    string = "charge.disputed"
+)
+
+type Checkout struct {
+	SessionId string
+	URL       string
+}
+
+// Event is a webhook event of a provider, Type is one of the Event constants
+// or the provider's own type when the event isn't handled.
+type Event struct {
+	Id              string `json:"id"`
+	Type            string `json:"type"`
+	SessionId       string `json:"session_id"`
+	PaymentIntentId string `json:"payment_intent_id"`
+	AmountRefunded  int    `json:"amount_refunded"`
+}
+
+type Provider interface {
+	CreateCheckout(userId int, amount int, credit int) (Checkout, error)
+	VerifyWebhook(payload []byte, header http.Header) error
+	ParseEvent(payload []byte) (Event, error)
+}
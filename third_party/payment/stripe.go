@@ -0,0 +1,136 @@
+package payment
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	stripeGo "github.com/stripe/stripe-go"
+	"github.com/stripe/stripe-go/checkout/session"
+	"github.com/stripe/stripe-go/webhook"
+)
+
+type Stripe struct {
+	WebhookSecret string
+	SuccessURL    string
+	CancelURL     string
+}
+
+func NewStripeProvider(secretKey string, webhookSecret string, successURL string, cancelURL string) *Stripe {
+	stripeGo.Key = secretKey
+
+	return &Stripe{
+		WebhookSecret: webhookSecret,
+		SuccessURL:    successURL,
+		CancelURL:     cancelURL,
+	}
+}
+
+// CreateCheckout creates a one-off payment of amount cents, the front-end
+// redirects to it with its session id.
+func (t *Stripe) CreateCheckout(userId int, amount int, credit int) (Checkout, error) {
+	params := &stripeGo.CheckoutSessionParams{
+		PaymentMethodTypes: stripeGo.StringSlice([]string{"card"}),
+		Mode:               stripeGo.String(string(stripeGo.CheckoutSessionModePayment)),
+		ClientReferenceID:  stripeGo.String(fmt.Sprint(userId)),
+		SuccessURL:         stripeGo.String(t.SuccessURL),
+		CancelURL:          stripeGo.String(t.CancelURL),
+		LineItems: []*stripeGo.CheckoutSessionLineItemParams{
+			{
+				Name:     stripeGo.String(fmt.Sprintf("%d crédits StandMaster", credit)),
+				Amount:   stripeGo.Int64(int64(amount)),
+				Currency: stripeGo.String(string(stripeGo.CurrencyEUR)),
+				Quantity: stripeGo.Int64(1),
+			},
+		},
+	}
+
+	s, err := session.New(params)
+	if err != nil {
+		return Checkout{}, err
+	}
+
+	return Checkout{
+		SessionId: s.ID,
+	}, nil
+}
+
+func (t *Stripe) VerifyWebhook(payload []byte, header http.Header) error {
+	_, err := webhook.ConstructEvent(payload, header.Get("Stripe-Signature"), t.WebhookSecret)
+
+	return err
+}
+
+// checkoutSession, charge and dispute only hold the fields of the Stripe
+// objects the webhook needs.
+type checkoutSession struct {
+	Id            string `json:"id"`
+	PaymentStatus string `json:"payment_status"`
+	PaymentIntent string `json:"payment_intent"`
+}
+
+type charge struct {
+	PaymentIntent  string `json:"payment_intent"`
+	AmountRefunded int    `json:"amount_refunded"`
+}
+
+type dispute struct {
+	PaymentIntent string `json:"payment_intent"`
+}
+
+func (t *Stripe) ParseEvent(payload []byte) (Event, error) {
+	var stripeEvent stripeGo.Event
+	if err := json.Unmarshal(payload, &stripeEvent); err != nil {
+		return Event{}, err
+	}
+	if stripeEvent.Data == nil {
+		return Event{}, fmt.Errorf("event %s has no data", stripeEvent.ID)
+	}
+
+	event := Event{
+		Id:   stripeEvent.ID,
+		Type: stripeEvent.Type,
+	}
+
+	switch stripeEvent.Type {
+	case "checkout.session.completed", "checkout.session.async_payment_succeeded", "checkout.session.async_payment_failed", "checkout.session.expired":
+		var session checkoutSession
+		if err := json.Unmarshal(stripeEvent.Data.Raw, &session); err != nil {
+			return Event{}, err
+		}
+		event.SessionId = session.Id
+		event.PaymentIntentId = session.PaymentIntent
+
+		switch stripeEvent.Type {
+		case "checkout.session.completed":
+			// delayed payment methods complete the checkout before the money is there
+			event.Type = EventCheckoutCompleted
+			if session.PaymentStatus == "unpaid" {
+				event.Type = EventCheckoutPending
+			}
+		case "checkout.session.async_payment_succeeded":
+			event.Type = EventCheckoutCompleted
+		case "checkout.session.async_payment_failed":
+			event.Type = EventCheckoutFailed
+		case "checkout.session.expired":
+			event.Type = EventCheckoutExpired
+		}
+	case "charge.refunded":
+		var charge charge
+		if err := json.Unmarshal(stripeEvent.Data.Raw, &charge); err != nil {
+			return Event{}, err
+		}
+		event.Type = EventChargeRefunded
+		event.PaymentIntentId = charge.PaymentIntent
+		event.AmountRefunded = charge.AmountRefunded
+	case "charge.dispute.created":
+		var dispute dispute
+		if err := json.Unmarshal(stripeEvent.Data.Raw, &dispute); err != nil {
+			return Event{}, err
+		}
+		event.Type = EventChargeDisputed
+		event.PaymentIntentId = dispute.PaymentIntent
+	}
+
+	return event, nil
+}
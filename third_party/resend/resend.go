@@ -2,12 +2,14 @@ package resend
 
 import (
 	"fmt"
+	"html"
 
 	resendGo "github.com/resend/resend-go/v2"
 )
 
 type ResendService interface {
 	SendInvitationEmail(to string, email string, password string) (*resendGo.SendEmailResponse, error)
+	SendNotificationEmail(to string, title string, message string) (*resendGo.SendEmailResponse, error)
 }
 
 type Resend struct {
@@ -43,3 +45,12 @@ func (t *Resend) SendInvitationEmail(to string, email string, password string) (
 
 	return t.sendEmail([]string{to}, "Invitation à rejoindre StandMaster", content)
 }
+
+func (t *Resend) SendNotificationEmail(to string, title string, message string) (*resendGo.SendEmailResponse, error) {
+	content := fmt.Sprintf(`
+    <p>%s</p>
+    <p>Retrouvez toutes vos notifications sur la platforme StandMaster.</p>
+  `, html.EscapeString(message))
+
+	return t.sendEmail([]string{to}, title, content)
+}
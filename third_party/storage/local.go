@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local stores the files in a directory of the server, for development and
+// tests. Files are served under publicURL by Handler, dir defaults to
+// "uploads".
+type Local struct {
+	dir       string
+	publicURL string
+}
+
+func NewLocalStorage(dir string, publicURL string) *Local {
+	if dir == "" {
+		dir = "uploads"
+	}
+
+	return &Local{
+		dir:       dir,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}
+}
+
+func (l *Local) Put(key string, content io.Reader, contentType string) error {
+	path := l.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, content); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	return file.Close()
+}
+
+func (l *Local) Delete(key string) error {
+	err := os.Remove(l.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+func (l *Local) URL(key string) string {
+	return l.publicURL + "/" + key
+}
+
+func (l *Local) Key(url string) (string, bool) {
+	return strings.CutPrefix(url, l.publicURL+"/")
+}
+
+// Handler serves the files of the directory, mount it on the path of
+// publicURL.
+func (l *Local) Handler() http.Handler {
+	return http.FileServer(http.Dir(l.dir))
+}
+
+// path keeps the key inside the directory of the storage.
+func (l *Local) path(key string) string {
+	return filepath.Join(l.dir, filepath.FromSlash(filepath.Clean("/"+key)))
+}
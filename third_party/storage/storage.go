@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"io"
+	"net/http"
+)
+
+// Storage keeps uploaded files, keys are slash separated paths such as
+// "stands/1/image.jpg".
+type Storage interface {
+	Put(key string, content io.Reader, contentType string) error
+	Delete(key string) error
+	// URL returns the public URL the file of the key is served at.
+	URL(key string) string
+	// Key returns the key of a URL returned by URL, false when the URL doesn't
+	// belong to the storage.
+	Key(url string) (string, bool)
+	// Handler serves the files, nil when they are served by another host.
+	Handler() http.Handler
+}